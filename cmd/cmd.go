@@ -4,26 +4,34 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/zhihanggg/gitdoc-cli/cmd/commit"
+	config_cmd "github.com/zhihanggg/gitdoc-cli/cmd/config"
 	"github.com/zhihanggg/gitdoc-cli/cmd/create"
+	"github.com/zhihanggg/gitdoc-cli/cmd/doctor"
+	"github.com/zhihanggg/gitdoc-cli/cmd/filter"
+	"github.com/zhihanggg/gitdoc-cli/cmd/hooks"
 	init_dev "github.com/zhihanggg/gitdoc-cli/cmd/init"
+	lfs_cmd "github.com/zhihanggg/gitdoc-cli/cmd/lfs"
+	"github.com/zhihanggg/gitdoc-cli/cmd/lock"
 	"github.com/zhihanggg/gitdoc-cli/cmd/push"
 	"github.com/zhihanggg/gitdoc-cli/cmd/state"
+	"github.com/zhihanggg/gitdoc-cli/cmd/watch"
+	"github.com/zhihanggg/gitdoc-cli/config"
 	"github.com/zhihanggg/gitdoc-cli/constant"
 	"github.com/zhihanggg/gitdoc-cli/entity/version"
 	"github.com/zhihanggg/gitdoc-cli/log"
 	"github.com/zhihanggg/gitdoc-cli/utils"
-	"gopkg.in/op/go-logging.v1"
 )
 
-const configPath = ".gitdoc-cli.yml"
-
 var (
 	printTrace bool
+	// configErrs 配置文件校验发现的问题
+	configErrs []error
 )
 
 var rootCmd = &cobra.Command{
@@ -38,29 +46,74 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	cobra.OnFinalize(closeLogFile)
 	rootCmd.PersistentFlags().BoolVar(&printTrace, "trace", false, "是否打印 trace 日志, 命令添加 --trace 打印 trace 日志")
-	// 如果子命令定义提供了PersistentPreRun函数，那么子命令的PersistentPreRun函数需要主动调用cmd.PersistentPreRun函数
-	rootCmd.PersistentPreRun = PersistentPreRun
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "只输出警告与错误")
+	rootCmd.PersistentFlags().CountP("verbose", "v", "输出更详细的日志，-v 输出调试信息，-vv 同时输出 trace 日志")
+	rootCmd.PersistentFlags().String("log-file", "", "将包括 trace 在内的全部日志以 json lines 格式追加到指定文件，终端输出不受影响")
+	// 如果子命令定义提供了PersistentPreRunE函数，那么子命令的PersistentPreRunE函数需要主动调用cmd.PersistentPreRunE函数
+	rootCmd.PersistentPreRunE = PersistentPreRunE
 }
 
-// initConfig 将配置文件中的参数读取到viper中
+// initConfig 将配置文件中的参数读取到viper中，并按 schema 校验配置文件
 func initConfig() {
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	config.SetDefaults()
+	if _, err := os.Stat(constant.ConfigFile); os.IsNotExist(err) {
 		return
 	}
 
-	viper.SetConfigFile(configPath)
+	viper.SetConfigFile(constant.ConfigFile)
 	if err := viper.ReadInConfig(); err != nil {
-		log.Warn("读取配置文件 %s 失败 %s， 本次执行将忽略该配置文件中的参数", configPath, err.Error())
-	} else {
-		log.Info("读取配置文件 %s 成功；提示：命令行参数的优先级要高于配置文件中同名参数的优先级", viper.ConfigFileUsed())
+		log.Warn("读取配置文件 %s 失败 %s， 本次执行将忽略该配置文件中的参数", constant.ConfigFile, err.Error())
+		return
+	}
+	log.Debug("读取配置文件 %s 成功；提示：命令行参数的优先级要高于配置文件中同名参数的优先级", viper.ConfigFileUsed())
+
+	file, err := config.Load(constant.ConfigFile)
+	if err != nil {
+		configErrs = []error{err}
+		return
 	}
+	values, err := file.Values()
+	if err != nil {
+		configErrs = []error{err}
+		return
+	}
+	configErrs = config.Validate(values, utils.GetFlagParamKeys(rootCmd))
 }
 
-// PersistentPreRun 各个子命令需要执行的一般操作，为了能让各个子命令都能自动执行该操作，rootCmd.PersistentPreRun被赋值为该函数
-func PersistentPreRun(cmd *cobra.Command, _ []string) {
+// PersistentPreRunE 各个子命令需要执行的一般操作，为了能让各个子命令都能自动执行该操作，rootCmd.PersistentPreRunE被赋值为该函数
+func PersistentPreRunE(cmd *cobra.Command, _ []string) error {
 	bindParams(cmd)
-	setLogLevel(cmd)
+	if err := setLogLevel(cmd); err != nil {
+		return err
+	}
+	return checkConfig(cmd)
+}
+
+// uncheckedCommands 配置文件存在问题时仍然执行的命令：config 命令用于修正配置；filter 与 hooks run 由 git 调用，
+// 终止执行会导致 git add、checkout、commit 等操作失败
+var uncheckedCommands = []string{"config.", "filter.", "hooks.run."}
+
+// checkConfig 配置文件存在问题时终止执行，uncheckedCommands 中的命令除外
+func checkConfig(cmd *cobra.Command) error {
+	if len(configErrs) == 0 {
+		return nil
+	}
+	prefix := utils.GetParamPrefix(cmd)
+	for _, unchecked := range uncheckedCommands {
+		if strings.HasPrefix(prefix, unchecked) {
+			// filter 对每个文件调用一次，只在 hooks run 时提示
+			if unchecked == "hooks.run." {
+				log.Warn("配置文件 %s 校验失败，可以执行 gitdoc-cli config validate 查看详情", constant.ConfigFile)
+			}
+			return nil
+		}
+	}
+	for _, err := range configErrs {
+		log.Error("%v", err)
+	}
+	return fmt.Errorf("配置文件 %s 校验失败，可以执行 gitdoc-cli config validate 查看详情", constant.ConfigFile)
 }
 
 // bindParams 将对应命令所能访问的命令行参数(Flag)绑定到viper上，后续可以通过viper访问这些参数；
@@ -79,21 +132,6 @@ func bindParams(cmd *cobra.Command) {
 	})
 }
 
-func setLogLevel(cmd *cobra.Command) {
-	var format = logging.MustStringFormatter(constant.LogFormat)
-	var backend = logging.AddModuleLevel(
-		logging.NewBackendFormatter(logging.NewLogBackend(os.Stderr, "", 0), format))
-	prefix := utils.GetParamPrefix(cmd)
-	if viper.GetBool(prefix + "trace") {
-		backend.SetLevel(logging.DEBUG, "")
-		p := log.DefaultStd.WithEnableTrace()
-		log.DefaultStd = &p
-	} else {
-		backend.SetLevel(logging.WARNING, "")
-	}
-	logging.SetBackend(backend)
-}
-
 // Execute 为具体脚手架命令的执行
 func Execute() {
 
@@ -102,6 +140,15 @@ func Execute() {
 	rootCmd.AddCommand(commit.NewCmd())
 	rootCmd.AddCommand(push.NewCmd())
 	rootCmd.AddCommand(state.NewCmd())
+	rootCmd.AddCommand(config_cmd.NewCmd())
+	rootCmd.AddCommand(watch.NewCmd())
+	rootCmd.AddCommand(hooks.NewCmd())
+	rootCmd.AddCommand(filter.NewCmd())
+	rootCmd.AddCommand(lfs_cmd.NewCmd())
+	rootCmd.AddCommand(doctor.NewCmd())
+	rootCmd.AddCommand(lock.NewCmd())
+	rootCmd.AddCommand(lock.NewUnlockCmd())
+	rootCmd.AddCommand(lock.NewLocksCmd())
 
 	err := rootCmd.Execute()
 
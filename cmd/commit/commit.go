@@ -2,24 +2,44 @@ package commit
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/locks"
 	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/pipeline"
+	"github.com/zhihanggg/gitdoc-cli/policy"
+	"github.com/zhihanggg/gitdoc-cli/scan"
 	"github.com/zhihanggg/gitdoc-cli/utils"
 )
 
+// stdin 读取用户输入，选择文档与输入 commit 信息共用，避免缓冲的输入丢失
+var stdin = bufio.NewReader(os.Stdin)
+
 func NewCmd() *cobra.Command {
 	impl := commitImpl{}
-	return &cobra.Command{
-		Use:   "commit",
+	commitCmd := &cobra.Command{
+		Use:   "commit [path]...",
 		Short: "commit 命令用来提交变更到远端",
-		Long:  "commit 命令用来提交变更到远端，会自动将doc/docx文件转换为markdown",
-		RunE:  impl.run(),
+		Long: "commit 命令用来提交变更到远端，会自动将doc/docx/odt/rtf/pptx/xlsx文件转换为markdown，并按 policy 配置检查变更的文档、按 sensitive 配置检查转换后的文本中的敏感信息。" +
+			"指定文档或目录时只转换并提交这些文档及其生成的 markdown 与图片；不指定时在终端中列出有变更的文档供选择",
+		RunE: impl.run(),
 	}
+	commitCmd.Flags().Bool("no-verify", false, "跳过提交规则、敏感信息与文档锁检查")
+	commitCmd.Flags().BoolP("all", "a", false, "不再选择文档，转换全部文档并提交全部变更")
+	commitCmd.Flags().Bool("dry-run", false, "只列出将要转换的文档、暂存的文件与创建的提交，不做任何修改")
+	commitCmd.Flags().Bool("force", false, "重新转换未变更的文档")
+	commitCmd.Flags().String("report", "", "将转换报告以 json 格式写入指定文件，如 report.json")
+	return commitCmd
 }
 
 type commitImpl struct {
@@ -27,18 +47,76 @@ type commitImpl struct {
 
 func (i *commitImpl) run() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		// 选择要提交的文档，sel 为 nil 时提交全部
+		all := viper.GetBool(utils.GetParamPrefix(cmd) + "all")
+		sel, err := selectDocs(args, all)
+		if err != nil {
+			return err
+		}
+		force := viper.GetBool(utils.GetParamPrefix(cmd) + "force")
+		if viper.GetBool(utils.GetParamPrefix(cmd) + "dry-run") {
+			return dryRun(sel, force)
+		}
+
+		// 获取仓库锁，避免与 watch 同时转换和提交
+		lock, err := git.AcquireLock()
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
 		// 转换文档
-		if err := convertDocToMd(); err != nil {
+		var selected, deleted []string
+		if sel != nil {
+			// 只删除文档时不需要转换
+			selected = append([]string{}, sel.Documents...)
+			deleted = append([]string{}, sel.Deleted...)
+		}
+		docs, renames, report, err := convertDocToMd(selected, deleted, force)
+		if report != nil {
+			if err := writeReport(report, viper.GetString(utils.GetParamPrefix(cmd)+"report")); err != nil {
+				return err
+			}
+		}
+		if err != nil {
 			return fmt.Errorf("转换文档失败: %v", err)
 		}
+		outputs := report.Outputs()
+
+		noVerify := viper.GetBool(utils.GetParamPrefix(cmd) + "no-verify")
+
+		// 检查转换后的文本中是否有敏感信息
+		if !noVerify {
+			if err := policy.VerifySensitive(outputs); err != nil {
+				return err
+			}
+		}
 
-		// 执行git add --all
-		if err := gitAdd(); err != nil {
+		// 执行git add，只提交部分文档时只暂存这些文档及其生成的文件
+		var paths []string
+		if sel != nil {
+			var staged []string
+			staged, paths = selectedPaths(*sel, docs, outputs, report.Removed(), renames)
+			if err := gitAdd(staged...); err != nil {
+				return fmt.Errorf("git add 失败: %v", err)
+			}
+		} else if err := gitAdd(); err != nil {
 			return fmt.Errorf("git add 失败: %v", err)
 		}
 
+		// 检查提交规则与文档锁
+		if !noVerify {
+			if err := policy.VerifyStaged(); err != nil {
+				return err
+			}
+			if err := locks.VerifyStaged(); err != nil {
+				return err
+			}
+		}
+
 		// 执行git commit
-		if err := gitCommit(); err != nil {
+		ctx := pipeline.Context{Documents: docs, Outputs: outputs}
+		if err := gitCommit(ctx, paths); err != nil {
 			return fmt.Errorf("git commit 失败: %v", err)
 		}
 
@@ -47,20 +125,68 @@ func (i *commitImpl) run() func(cmd *cobra.Command, args []string) error {
 	}
 }
 
-// gitAdd 执行git add --all
-func gitAdd() error {
-	if _, err := utils.ExecCmd("git add --all"); err != nil {
-		return fmt.Errorf("git add 失败: %v", err)
+// selectDocs 按命令行参数选择要提交的文档；没有参数时在终端中列出有变更的文档供选择，
+// 返回 nil 表示转换全部文档并提交全部变更
+func selectDocs(args []string, all bool) (*selection, error) {
+	if all || len(args) == 0 && !utils.IsTerminal(os.Stdin) {
+		return nil, nil
 	}
-	return nil
+	docs, err := scan.Documents(scan.OptionsFromConfig(), convert.Extensions())
+	if err != nil {
+		return nil, fmt.Errorf("扫描文档文件失败: %v", err)
+	}
+	for n, doc := range docs {
+		docs[n] = filepath.Clean(doc)
+	}
+	if len(args) > 0 {
+		sel, err := resolveDocs(args, docs)
+		if err != nil {
+			return nil, err
+		}
+		return &sel, nil
+	}
+	picked, err := pickDocs(docs)
+	if err != nil || picked == nil {
+		return nil, err
+	}
+	return &selection{Documents: picked}, nil
+}
+
+// selectedPaths 返回只提交部分文档时需要暂存的文件，以及需要提交的文件：文档、生成的 markdown 与其引用的图片、
+// 删除的文档及其生成的文件，以及重命名前的文档与生成的文件
+func selectedPaths(sel selection, docs, outputs, removed []string, renames []convert.Rename) (staged, committed []string) {
+	staged = append(staged, docs...)
+	staged = append(staged, outputs...)
+	staged = append(staged, convert.MediaFiles(outputs)...)
+	staged = append(staged, sel.Deleted...)
+	committed = append(committed, staged...)
+	for _, p := range removed {
+		// 已通过 git rm 暂存，未提交过的文件不需要提交
+		if git.Exists("HEAD", "./"+filepath.ToSlash(p)) {
+			committed = append(committed, p)
+		}
+	}
+	for _, r := range renames {
+		if git.Tracked(r.From) {
+			staged = append(staged, r.From)
+			committed = append(committed, r.From)
+		}
+		// 已通过 git mv 暂存，只需要一并提交
+		committed = append(committed, r.Moved...)
+	}
+	return staged, committed
 }
 
-// gitCommit 执行git commit
-func gitCommit() error {
+// gitAdd 执行git add，paths 为空时加入全部变更
+func gitAdd(paths ...string) error {
+	return git.Add(paths...)
+}
+
+// gitCommit 执行git commit，前后分别执行 hooks.pre_commit 与 hooks.post_commit；指定 paths 时只提交这些文件
+func gitCommit(ctx pipeline.Context, paths []string) error {
 	// 获取用户输入的commit信息
-	log.Info("请输入本次变更信息:")
-	reader := bufio.NewReader(os.Stdin)
-	commitMsg, err := reader.ReadString('\n')
+	log.Prompt("请输入本次变更信息:")
+	commitMsg, err := stdin.ReadString('\n')
 	if err != nil {
 		return fmt.Errorf("读取commit信息失败: %v", err)
 	}
@@ -70,37 +196,106 @@ func gitCommit() error {
 		return fmt.Errorf("commit信息不能为空")
 	}
 
+	commitMsg, err = ApplyTemplate(commitMsg)
+	if err != nil {
+		return err
+	}
+
+	ctx.Message = commitMsg
+	if err := pipeline.Run(pipeline.PreCommit, ctx); err != nil {
+		return err
+	}
+
 	// 执行git commit
 	log.Debug("执行 git commit...")
-	if _, err := utils.ExecCmd(fmt.Sprintf("git commit -m \"%s\"", commitMsg)); err != nil {
-		return fmt.Errorf("git commit 失败: %v", err)
+	if err := git.Commit(commitMsg, paths...); err != nil {
+		return err
 	}
-	return nil
+	return pipeline.Run(pipeline.PostCommit, ctx)
 }
 
-// convertDocToMd 将doc/docx文件转换为markdown
-func convertDocToMd() error {
-	// 扫描doc/docx文件
-	log.Debug("开始扫描文档文件...")
-	docFiles, err := utils.ScanFilesByExt(".", []string{".doc", ".docx"})
+// ApplyTemplate 按配置的 commit 信息模板生成最终的 commit 信息
+func ApplyTemplate(msg string) (string, error) {
+	text := viper.GetString(config.KeyCommitTemplate)
+	if text == "" {
+		return msg, nil
+	}
+	tmpl, err := template.New("commit").Parse(text)
 	if err != nil {
-		return fmt.Errorf("扫描文档文件失败: %v", err)
+		return "", fmt.Errorf("解析 commit 信息模板失败: %v", err)
 	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Message string }{Message: msg}); err != nil {
+		return "", fmt.Errorf("生成 commit 信息失败: %v", err)
+	}
+	return buf.String(), nil
+}
 
-	if len(docFiles) == 0 {
-		log.Debug("未找到需要转换的文档文件")
-	} else {
+// convertDocToMd 将doc/docx等文档转换为markdown，前后分别执行 hooks.pre_convert 与 hooks.post_convert。
+// selected 为 nil 时转换扫描到的全部文档，并删除全部源文档已不存在的 markdown；否则只删除 deleted 中的文档生成的 markdown。
+// force 为 true 时重新转换未变更的文档。返回转换的文档、重命名的文档及转换报告，有文档转换失败时同时返回报告与错误
+func convertDocToMd(selected, deleted []string, force bool) ([]string, []convert.Rename, *convert.Report, error) {
+	report := convert.NewReport()
+	docFiles := selected
+	if selected == nil {
+		// 扫描doc/docx文件
+		log.Debug("开始扫描文档文件...")
+		var err error
+		docFiles, err = scan.Documents(scan.OptionsFromConfig(), convert.Extensions())
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("扫描文档文件失败: %v", err)
+		}
+		deleted = nil
+	}
+
+	var renames []convert.Rename
+	if len(docFiles) > 0 {
 		log.Debug("找到 %d 个文档文件，开始转换...", len(docFiles))
+		if err := pipeline.Run(pipeline.PreConvert, pipeline.Context{Documents: docFiles}); err != nil {
+			return nil, nil, nil, err
+		}
 
-		// 转换所有文档为markdown
-		for _, docFile := range docFiles {
-			mdFile := strings.TrimSuffix(docFile, filepath.Ext(docFile)) + ".md"
-			log.Debug("正在转换: %s -> %s", docFile, mdFile)
+		// 重命名的文档沿用原来生成的 markdown，使 git log --follow 能够追踪
+		var err error
+		if renames, err = convert.MoveRenamed(docFiles); err != nil {
+			return nil, nil, nil, err
+		}
 
-			if err := utils.ConvertDocToMarkdown(docFile, mdFile); err != nil {
-				return fmt.Errorf("转换文件 %s 失败: %v", docFile, err)
-			}
+		// 转换所有文档为markdown，未变更的文档沿用之前生成的文件
+		report.Add(convert.Files(docFiles, force)...)
+	} else {
+		log.Debug("未找到需要转换的文档文件")
+	}
+
+	// 删除已删除的文档生成的文件
+	removed, err := convert.RemoveStale(deleted)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	report.Add(removed...)
+	report.Finish()
+	if err := report.Err(); err != nil {
+		return nil, nil, report, err
+	}
+
+	if len(docFiles) > 0 {
+		ctx := pipeline.Context{Documents: docFiles, Outputs: report.Outputs()}
+		if err := pipeline.Run(pipeline.PostConvert, ctx); err != nil {
+			return nil, nil, report, err
 		}
 	}
+	return docFiles, renames, report, nil
+}
+
+// writeReport 打印转换报告，指定 p 时同时以 json 格式写入文件
+func writeReport(report *convert.Report, p string) error {
+	report.Print()
+	if p == "" {
+		return nil
+	}
+	if err := report.WriteJSON(p); err != nil {
+		return err
+	}
+	log.Debug("转换报告已写入 %s", p)
 	return nil
 }
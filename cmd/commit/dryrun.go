@@ -0,0 +1,114 @@
+package commit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/scan"
+)
+
+// dryRun 列出将要转换的文档、暂存的文件与创建的提交，不修改工作区与暂存区；sel 为 nil 时对应提交全部。
+// 与 convertDocToMd 使用相同的跳过规则与生成文件，force 为 true 时重新转换未变更的文档
+func dryRun(sel *selection, force bool) error {
+	var docs, deleted []string
+	if sel != nil {
+		docs = sel.Documents
+		deleted = append([]string{}, sel.Deleted...)
+	} else {
+		var err error
+		docs, err = scan.Documents(scan.OptionsFromConfig(), convert.Extensions())
+		if err != nil {
+			return fmt.Errorf("扫描文档文件失败: %v", err)
+		}
+	}
+
+	renames, err := convert.DetectRenames(docs)
+	if err != nil {
+		return err
+	}
+	for _, r := range renames {
+		log.Normal("将重命名 %s -> %s，沿用 %s", r.From, r.To, r.Output)
+	}
+
+	report := convert.NewReport()
+	report.Add(convert.Plan(docs, force)...)
+	stale, err := convert.StaleOutputs(deleted)
+	if err != nil {
+		return err
+	}
+	report.Add(stale...)
+
+	// 只有需要转换的文档会生成新的文件，删除的文件也会被暂存
+	changed := make(map[string]bool)
+	for _, res := range report.Results {
+		switch res.Status {
+		case convert.StatusSkipped:
+			log.Normal("将跳过 %s，文档未变更", res.Source)
+		case convert.StatusConverted:
+			log.Normal("将转换 %s -> %s", res.Source, strings.Join(res.Outputs, ", "))
+		case convert.StatusRemoved:
+			log.Normal("将删除 %s 生成的 %s", res.Source, strings.Join(res.Outputs, ", "))
+		case convert.StatusFailed:
+			log.Warn("%s 将转换失败: %s", res.Source, res.Error)
+		}
+		if res.Status == convert.StatusConverted || res.Status == convert.StatusRemoved {
+			for _, p := range res.Outputs {
+				changed[p] = true
+			}
+		}
+	}
+	outputs := report.Outputs()
+	removed := report.Removed()
+
+	changes, err := git.WorktreeChanges()
+	if err != nil {
+		return err
+	}
+	// 未变更的文件不会被暂存
+	for _, p := range changes {
+		changed[p] = true
+	}
+	candidates := append(append(append([]string{}, changes...), outputs...), removed...)
+	if sel != nil {
+		candidates, _ = selectedPaths(*sel, docs, outputs, removed, renames)
+	}
+	var staged []string
+	for _, p := range dedup(candidates) {
+		if changed[p] {
+			staged = append(staged, p)
+		}
+	}
+	if len(staged) == 0 {
+		log.Normal("没有需要提交的变更")
+		log.Info("试运行，未修改工作区与暂存区")
+		return nil
+	}
+	log.Normal("将暂存 %d 个文件:", len(staged))
+	for _, p := range staged {
+		log.Normal("  %s", p)
+	}
+
+	branch := git.Branch()
+	if branch == "" {
+		branch = "HEAD"
+	}
+	log.Normal("将在 %s 上创建提交，转换 %d 个文档，共 %d 个文件", branch, report.Count(convert.StatusConverted), len(staged))
+	log.Info("试运行，未修改工作区与暂存区")
+	return nil
+}
+
+// dedup 排序并去除重复的路径
+func dedup(paths []string) []string {
+	sort.Strings(paths)
+	result := paths[:0]
+	for n, p := range paths {
+		if n == 0 || p != paths[n-1] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package commit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+)
+
+// selection 本次要提交的文档
+type selection struct {
+	// Documents 需要转换并提交的文档
+	Documents []string
+	// Deleted 已删除、需要提交删除的文档
+	Deleted []string
+}
+
+// resolveDocs 将命令行中的路径解析为文档，目录会展开为其中的全部文档；
+// 路径不存在但已被 git 跟踪时提交其删除
+func resolveDocs(args, docs []string) (selection, error) {
+	var sel selection
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		arg = filepath.Clean(arg)
+		matched := false
+		for _, doc := range docs {
+			if doc == arg || isUnder(doc, arg) {
+				matched = true
+				if !seen[doc] {
+					seen[doc] = true
+					sel.Documents = append(sel.Documents, doc)
+				}
+			}
+		}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			deleted, err := deletedDocs(arg)
+			if err != nil {
+				return selection{}, err
+			}
+			sel.Deleted = append(sel.Deleted, deleted...)
+			matched = matched || len(deleted) > 0
+		} else if os.IsNotExist(err) && git.Tracked(arg) {
+			sel.Deleted = append(sel.Deleted, arg)
+			matched = true
+		}
+		if !matched {
+			return selection{}, fmt.Errorf("未找到文档 %s", arg)
+		}
+	}
+	return sel, nil
+}
+
+// deletedDocs 返回目录 dir 下已删除、尚未提交删除的文档
+func deletedDocs(dir string) ([]string, error) {
+	changes, err := git.WorktreeChanges()
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for _, p := range changes {
+		if _, ok := convert.Lookup(p); !ok || !isUnder(p, dir) {
+			continue
+		}
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			deleted = append(deleted, p)
+		}
+	}
+	return deleted, nil
+}
+
+// isUnder 路径 p 是否位于目录 dir 下
+func isUnder(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// pickDocs 列出有变更的文档供用户选择，直接回车或没有变更的文档时返回 nil，表示提交全部
+func pickDocs(docs []string) ([]string, error) {
+	changes, err := git.WorktreeChanges()
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]bool, len(changes))
+	for _, p := range changes {
+		changed[filepath.Clean(p)] = true
+	}
+	var candidates []string
+	for _, doc := range docs {
+		if changed[doc] {
+			candidates = append(candidates, doc)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	log.Prompt("有变更的文档:")
+	for n, doc := range candidates {
+		log.Normal("  %d) %s", n+1, doc)
+	}
+	log.Prompt("请选择要提交的文档（如 1,3 或 1-3，直接回车提交全部）:")
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return nil, fmt.Errorf("读取选择失败: %v", err)
+	}
+	indexes, err := parseChoice(strings.TrimSpace(answer), len(candidates))
+	if err != nil {
+		return nil, err
+	}
+	if indexes == nil {
+		return nil, nil
+	}
+	picked := make([]string, 0, len(indexes))
+	for _, n := range indexes {
+		picked = append(picked, candidates[n])
+	}
+	return picked, nil
+}
+
+// parseChoice 解析 1,3 或 1-3 形式的选择，返回从 0 开始的序号，输入为空时返回 nil
+func parseChoice(answer string, count int) ([]int, error) {
+	if answer == "" {
+		return nil, nil
+	}
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool {
+		return r == ',' || r == '，' || r == ' '
+	}) {
+		from, to, isRange := strings.Cut(field, "-")
+		start, err := strconv.Atoi(from)
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.Atoi(to)
+		}
+		if err != nil || start < 1 || end > count || start > end {
+			return nil, fmt.Errorf("无效的选择 %s，请输入 1 到 %d 之间的序号", field, count)
+		}
+		for n := start; n <= end; n++ {
+			if !seen[n] {
+				seen[n] = true
+				indexes = append(indexes, n-1)
+			}
+		}
+	}
+	return indexes, nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	conf "github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// NewCmd 返回 config 相关子命令
+func NewCmd() *cobra.Command {
+	impl := configImpl{}
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "config 命令用来查看和修改配置文件 " + constant.ConfigFile,
+		Long:  "config 命令用来查看和修改配置文件 " + constant.ConfigFile + "，所有配置项都会按 schema 校验",
+	}
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "查看配置项当前生效的值",
+		Args:  cobra.ExactArgs(1),
+		RunE:  impl.get(),
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "设置配置项，列表类型的值以逗号分隔",
+		Args:  cobra.ExactArgs(2),
+		RunE:  impl.set(),
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "unset <key>",
+		Short: "删除配置项，删除后使用默认值",
+		Args:  cobra.ExactArgs(1),
+		RunE:  impl.unset(),
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "列出全部配置项及当前生效的值",
+		Args:  cobra.NoArgs,
+		RunE:  impl.list(),
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "校验配置文件",
+		Args:  cobra.NoArgs,
+		RunE:  impl.validate(),
+	})
+	return configCmd
+}
+
+type configImpl struct {
+}
+
+func (i *configImpl) get() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		key, err := lookup(args[0])
+		if err != nil {
+			return err
+		}
+		log.Normal("%v", viper.Get(key.Name))
+		return nil
+	}
+}
+
+func (i *configImpl) set() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		key, err := lookup(args[0])
+		if err != nil {
+			return err
+		}
+		value, err := conf.ParseValue(key, args[1])
+		if err != nil {
+			return err
+		}
+		file, err := conf.Load(constant.ConfigFile)
+		if err != nil {
+			return err
+		}
+		if err := file.Set(key.Name, value); err != nil {
+			return err
+		}
+		if err := file.Save(); err != nil {
+			return err
+		}
+		log.Info("已设置 %s = %v", key.Name, value)
+		return nil
+	}
+}
+
+func (i *configImpl) unset() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		file, err := conf.Load(constant.ConfigFile)
+		if err != nil {
+			return err
+		}
+		name := strings.ToLower(args[0])
+		if !file.Unset(name) {
+			log.Warn("配置文件 %s 中没有配置项 %s", constant.ConfigFile, name)
+			return nil
+		}
+		if err := file.Save(); err != nil {
+			return err
+		}
+		log.Info("已删除配置项 %s", name)
+		return nil
+	}
+}
+
+func (i *configImpl) list() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		file, err := conf.Load(constant.ConfigFile)
+		if err != nil {
+			return err
+		}
+		for _, key := range conf.Keys() {
+			source := "默认"
+			if _, ok := file.Get(key.Name); ok {
+				source = "配置文件"
+			}
+			log.Normal("%s = %v", log.Color(log.Green, "%s", key.Name), viper.Get(key.Name))
+			log.Normal("    %s (%s, %s)", key.Usage, key.Kind, source)
+		}
+		return nil
+	}
+}
+
+func (i *configImpl) validate() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		file, err := conf.Load(constant.ConfigFile)
+		if err != nil {
+			return err
+		}
+		values, err := file.Values()
+		if err != nil {
+			return err
+		}
+		errs := conf.Validate(values, utils.GetFlagParamKeys(cmd.Root()))
+		if len(errs) == 0 {
+			log.Debug("配置文件 %s 校验通过", constant.ConfigFile)
+			return nil
+		}
+		for _, err := range errs {
+			log.Error("%v", err)
+		}
+		return fmt.Errorf("配置文件 %s 存在 %d 个问题", constant.ConfigFile, len(errs))
+	}
+}
+
+// lookup 查找配置项，找不到时给出最相近的配置项
+func lookup(name string) (conf.Key, error) {
+	key, ok := conf.Lookup(name)
+	if ok {
+		return key, nil
+	}
+	if suggestion := conf.Suggest(name); suggestion != "" {
+		return key, fmt.Errorf("未知的配置项 %s，是否想设置 %s", name, suggestion)
+	}
+	return key, fmt.Errorf("未知的配置项 %s，可以执行 gitdoc-cli config list 查看全部配置项", name)
+}
@@ -2,7 +2,10 @@ package create
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	lfs_cmd "github.com/zhihanggg/gitdoc-cli/cmd/lfs"
 	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/utils"
 )
 
 var (
@@ -19,6 +22,7 @@ func NewCmd() *cobra.Command {
 		RunE:  impl.run(),
 	}
 	createCmd.PersistentFlags().StringVar(&projectName, "project-name", "", "项目英文名")
+	createCmd.Flags().Bool("lfs", false, "以 Git LFS 存储 docx/pptx/xlsx 与导出的媒体文件")
 	return createCmd
 }
 
@@ -28,6 +32,9 @@ type createImpl struct {
 func (c *createImpl) run() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		log.Info("create project: %s", projectName)
+		if viper.GetBool(utils.GetParamPrefix(cmd) + "lfs") {
+			return lfs_cmd.Setup()
+		}
 		return nil
 	}
 }
@@ -0,0 +1,138 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zhihanggg/gitdoc-cli/cmd/filter"
+	"github.com/zhihanggg/gitdoc-cli/cmd/state"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/githooks"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// NewCmd 返回 doctor 命令
+func NewCmd() *cobra.Command {
+	impl := doctorImpl{}
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "doctor 命令用来检查 gitdoc-cli 的运行环境",
+		Long:  "doctor 命令用来检查 git、pandoc、git 用户信息、git hooks、docx filter 与 Git LFS 是否正确配置",
+		Args:  cobra.NoArgs,
+		RunE:  impl.run(),
+	}
+}
+
+type doctorImpl struct {
+}
+
+// check 一项检查，返回的错误会作为问题输出
+type check struct {
+	name string
+	run  func() error
+}
+
+func (i *doctorImpl) run() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		checks := []check{
+			{name: "git", run: checkCommand("git --version")},
+			{name: "pandoc", run: checkCommand("pandoc --version")},
+			{name: "git 用户信息", run: checkUser},
+			{name: "git hooks", run: checkHooks},
+			{name: "docx filter", run: checkFilter},
+			{name: "Git LFS", run: state.CheckLFS},
+		}
+		failed := 0
+		for _, c := range checks {
+			log.Debug("检查 %s...", c.name)
+			if err := c.run(); err != nil {
+				failed++
+				log.Error("%s: %v", c.name, err)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("发现 %d 个问题", failed)
+		}
+		log.Info("运行环境检查通过")
+		return nil
+	}
+}
+
+// checkCommand 检查命令能否执行，并输出其第一行输出
+func checkCommand(cmd string) func() error {
+	return func() error {
+		output, err := utils.ExecCmd(cmd)
+		if err != nil {
+			return fmt.Errorf("未安装，可以执行 gitdoc-cli init 安装")
+		}
+		version, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+		log.Info("%s", version)
+		return nil
+	}
+}
+
+// checkUser 检查是否设置了 git 用户名与邮箱
+func checkUser() error {
+	for _, key := range []string{"user.name", "user.email"} {
+		output, err := utils.ExecCmd("git config --get " + key)
+		if err != nil || strings.TrimSpace(output) == "" {
+			return fmt.Errorf("未设置 %s，可以执行 gitdoc-cli init 设置", key)
+		}
+	}
+	log.Info("git 用户信息已设置")
+	return nil
+}
+
+// checkHooks 检查 git hooks 的安装状态，未安装不算问题，但记录的 gitdoc-cli 路径不存在时会导致提交失败
+func checkHooks() error {
+	statuses, err := githooks.List()
+	if err != nil {
+		return err
+	}
+	var installed []string
+	for _, s := range statuses {
+		if s.State != githooks.Installed {
+			continue
+		}
+		installed = append(installed, s.Name)
+		if _, err := os.Stat(s.Binary); err != nil {
+			log.Warn("%s 记录的 %s 不存在，将使用 PATH 中的 gitdoc-cli", s.Name, s.Binary)
+		}
+	}
+	if len(installed) == 0 {
+		log.Info("未安装 git hooks，git commit 不会自动转换文档，可以执行 gitdoc-cli hooks install 安装")
+		return nil
+	}
+	log.Info("已安装 git hooks: %s", strings.Join(installed, ", "))
+	return nil
+}
+
+// checkFilter 检查 .gitattributes 中启用的 docx filter 是否已在 git 配置中注册
+func checkFilter() error {
+	root, err := git.TopLevel()
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(filepath.Join(root, ".gitattributes"))
+	if err != nil || !strings.Contains(string(content), "filter="+filter.Name) {
+		log.Info("仓库未启用 docx filter")
+		return nil
+	}
+	if !filter.Configured() {
+		return fmt.Errorf(".gitattributes 中启用了 docx filter，但 git 配置中未注册，可以执行 gitdoc-cli init --filter 注册")
+	}
+	// 后面的规则（如 git lfs track 添加的 filter=lfs）会覆盖 filter=gitdoc，以实际生效的属性为准
+	effective, err := git.Attr(filepath.Join(root, "gitdoc-sample.docx"), "filter")
+	if err != nil {
+		return err
+	}
+	if effective != filter.Name {
+		return fmt.Errorf(".gitattributes 中启用了 docx filter，但 docx 实际生效的是 filter=%s，请检查 .gitattributes 中后面的规则", effective)
+	}
+	log.Info("docx filter 已注册")
+	return nil
+}
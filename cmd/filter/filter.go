@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/office"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// Name .gitattributes 中使用的 filter 名称
+const Name = "gitdoc"
+
+// Patterns 使用 filter 存储的文档
+var Patterns = []string{"*.docx"}
+
+// NewCmd 返回 filter 相关子命令
+func NewCmd() *cobra.Command {
+	impl := filterImpl{}
+	filterCmd := &cobra.Command{
+		Use:   "filter",
+		Short: "filter 命令由 git 调用，将 docx 以不压缩的形式存储，使 git 的增量压缩能够生效",
+		Long: "filter 命令实现 git 的 clean/smudge filter：clean 在暂存时将 docx 的 XML 部件规范化并不压缩存储，" +
+			"smudge 在检出时重新压缩为常规的 docx；clean 会去掉 Word 记录编辑会话的 rsid，检出的 docx 内容不变，" +
+			"但与暂存前并不逐字节相同；可以通过 gitdoc-cli init --filter 配置",
+	}
+	filterCmd.AddCommand(&cobra.Command{
+		Use:   "clean [file]",
+		Short: "从标准输入读取 docx，输出规范化且不压缩的形式",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  impl.run(office.Clean),
+	})
+	filterCmd.AddCommand(&cobra.Command{
+		Use:   "smudge [file]",
+		Short: "从标准输入读取 clean 的输出，还原为常规的 docx",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  impl.run(office.Smudge),
+	})
+	return filterCmd
+}
+
+type filterImpl struct {
+}
+
+// run git 通过标准输入输出与 filter 交换文件内容，args 中的文件名仅用于日志
+func (i *filterImpl) run(transform func([]byte) ([]byte, error)) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("读取标准输入失败: %v", err)
+		}
+		output, err := transform(content)
+		if err != nil {
+			// 无法解析的文档原样交给 git，避免阻塞暂存与检出
+			name := "文档"
+			if len(args) > 0 {
+				name = args[0]
+			}
+			log.Warn("处理 %s 失败，将原样存储: %v", name, err)
+			output = content
+		}
+		if _, err := os.Stdout.Write(output); err != nil {
+			return fmt.Errorf("写入标准输出失败: %v", err)
+		}
+		return nil
+	}
+}
+
+// Setup 在全局 git 配置中注册 filter，binary 为 gitdoc-cli 的路径
+func Setup(binary string) error {
+	settings := [][2]string{
+		{"filter." + Name + ".clean", utils.ShellQuote(binary) + " filter clean %f"},
+		{"filter." + Name + ".smudge", utils.ShellQuote(binary) + " filter smudge %f"},
+	}
+	for _, s := range settings {
+		cmd := fmt.Sprintf("git config --global %s %s", s[0], utils.ShellQuote(s[1]))
+		if _, err := utils.ExecCmd(cmd); err != nil {
+			return fmt.Errorf("设置 git 配置 %s 失败: %v", s[0], err)
+		}
+	}
+	return nil
+}
+
+// Configured 当前仓库生效的 git 配置（包括全局与仓库配置）中是否已注册 filter
+func Configured() bool {
+	output, err := utils.ExecCmd("git config --get filter." + Name + ".clean")
+	return err == nil && strings.TrimSpace(output) != ""
+}
+
+// AddAttributes 在 .gitattributes 中为 Patterns 启用 filter，已存在的行不会重复添加，返回新增的行
+func AddAttributes(p string) ([]string, error) {
+	content, err := os.ReadFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取 %s 失败: %v", p, err)
+	}
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		existing[strings.Join(strings.Fields(line), " ")] = true
+	}
+	var added []string
+	for _, pattern := range Patterns {
+		line := fmt.Sprintf("%s filter=%s", pattern, Name)
+		if !existing[line] {
+			added = append(added, line)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	text := string(content)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	text += strings.Join(added, "\n") + "\n"
+	if err := os.WriteFile(p, []byte(text), 0644); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %v", p, err)
+	}
+	return added, nil
+}
@@ -0,0 +1,139 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/githooks"
+	"github.com/zhihanggg/gitdoc-cli/log"
+)
+
+// NewCmd 返回 hooks 相关子命令
+func NewCmd() *cobra.Command {
+	impl := hooksImpl{}
+	hooksCmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "hooks 命令用来安装 git hooks，使 git commit 也能自动转换文档",
+		Long:  "hooks 命令用来安装 pre-commit、commit-msg、pre-push 三个 git hooks，已存在的 hooks 会被串联执行而不是覆盖",
+	}
+	hooksCmd.AddCommand(&cobra.Command{
+		Use:   "install",
+		Short: "安装 git hooks",
+		Args:  cobra.NoArgs,
+		RunE:  impl.install(),
+	})
+	hooksCmd.AddCommand(&cobra.Command{
+		Use:   "uninstall",
+		Short: "卸载 git hooks，并恢复被串联的 hooks",
+		Args:  cobra.NoArgs,
+		RunE:  impl.uninstall(),
+	})
+	hooksCmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "查看 git hooks 的安装状态",
+		Args:  cobra.NoArgs,
+		RunE:  impl.status(),
+	})
+	hooksCmd.AddCommand(&cobra.Command{
+		Use:    "run <hook> [args...]",
+		Short:  "由 git hooks 调用，执行对应的转换与检查",
+		Args:   cobra.MinimumNArgs(1),
+		Hidden: true,
+		// git 传入的参数原样转交给对应的 hook
+		DisableFlagParsing: true,
+		RunE:               impl.run(),
+	})
+	return hooksCmd
+}
+
+type hooksImpl struct {
+}
+
+func (i *hooksImpl) install() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		binary, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("获取 gitdoc-cli 路径失败: %v", err)
+		}
+		statuses, err := githooks.Install(binary)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+		log.Info("git hooks 安装成功")
+		return nil
+	}
+}
+
+func (i *hooksImpl) uninstall() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		statuses, err := githooks.Uninstall()
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+		log.Info("git hooks 卸载成功")
+		return nil
+	}
+}
+
+func (i *hooksImpl) status() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		statuses, err := githooks.List()
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+		return nil
+	}
+}
+
+func (i *hooksImpl) run() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		// 由 gitdoc-cli 自身发起的 git 命令已经完成了转换，也已执行过配置的 hooks
+		internal := os.Getenv(constant.EnvInternal) != ""
+		switch args[0] {
+		case "pre-commit":
+			if internal {
+				return nil
+			}
+			return preCommit()
+		case "commit-msg":
+			if internal {
+				return nil
+			}
+			if len(args) < 2 {
+				return fmt.Errorf("commit-msg 缺少提交信息文件参数")
+			}
+			return commitMsg(args[1])
+		case "pre-push":
+			return prePush(os.Stdin, !internal)
+		default:
+		}
+		return fmt.Errorf("不支持的 hook: %s", args[0])
+	}
+}
+
+// printStatus 打印 hooks 状态
+func printStatus(statuses []githooks.Status) {
+	for _, s := range statuses {
+		switch s.State {
+		case githooks.Installed:
+			msg := "已安装"
+			if s.Chained {
+				msg += "，串联了已存在的 hook"
+			}
+			if _, err := os.Stat(s.Binary); err != nil {
+				log.Warn("%s: %s，但 %s 不存在，将使用 PATH 中的 gitdoc-cli", s.Name, msg, s.Binary)
+				continue
+			}
+			log.Normal("%s: %s", s.Name, msg)
+		case githooks.Foreign:
+			log.Warn("%s: 存在其他 hook，安装时会串联执行", s.Name)
+		default:
+			log.Normal("%s: 未安装", s.Name)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package hooks
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/cmd/commit"
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/locks"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/pipeline"
+	"github.com/zhihanggg/gitdoc-cli/policy"
+	"github.com/zhihanggg/gitdoc-cli/scan"
+)
+
+// zeroSha 推送新分支或删除分支时 git 使用的空提交
+const zeroSha = "0000000000000000000000000000000000000000"
+
+// documents 过滤出需要转换的文档
+func documents(files []string) []string {
+	opts := scan.OptionsFromConfig()
+	var docs []string
+	for _, f := range files {
+		if _, ok := convert.Lookup(f); ok && !scan.Ignored(opts, f) {
+			docs = append(docs, f)
+		}
+	}
+	return docs
+}
+
+// preCommit 转换暂存区中的文档，并将生成的文件加入暂存区，同时执行配置的 hooks.pre_convert、
+// hooks.post_convert 与 hooks.pre_commit，提交前按 policy 配置检查暂存区
+func preCommit() error {
+	lock, err := git.AcquireLock()
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	staged, err := git.StagedFiles()
+	if err != nil {
+		return err
+	}
+	deleted, err := git.StagedDeleted()
+	if err != nil {
+		return err
+	}
+	ctx := pipeline.Context{}
+	if docs, removed := documents(staged), documents(deleted); len(docs) > 0 || len(removed) > 0 {
+		if ctx, err = convertStaged(docs, removed); err != nil {
+			return err
+		}
+	}
+	if err := policy.VerifyStaged(); err != nil {
+		return err
+	}
+	if err := locks.VerifyStaged(); err != nil {
+		return err
+	}
+	return pipeline.Run(pipeline.PreCommit, ctx)
+}
+
+// convertStaged 转换暂存区中的文档，并将生成的文件加入暂存区；删除暂存区中已删除的文档生成的文件
+func convertStaged(docs, deleted []string) (pipeline.Context, error) {
+	report := convert.NewReport()
+	var restage []string
+	if len(docs) > 0 {
+		if err := pipeline.Run(pipeline.PreConvert, pipeline.Context{Documents: docs}); err != nil {
+			return pipeline.Context{}, err
+		}
+		if _, err := convert.MoveRenamed(docs); err != nil {
+			return pipeline.Context{}, err
+		}
+		before := digests(docs)
+		report.Add(convert.Files(docs, false)...)
+		// 转换前可能清理了个人信息或写入了文档 ID，只重新暂存被修改的文档，避免带上未暂存的修改
+		for _, doc := range docs {
+			if sum, ok := before[doc]; ok && digest(doc) != sum {
+				log.Info("转换前修改了 %s（清理个人信息或写入文档 ID），已重新暂存", doc)
+				restage = append(restage, doc)
+			}
+		}
+	}
+	removed, err := convert.RemoveStale(append([]string{}, deleted...))
+	if err != nil {
+		return pipeline.Context{}, err
+	}
+	report.Add(removed...)
+	report.Finish()
+	report.Print()
+	if err := report.Err(); err != nil {
+		return pipeline.Context{}, err
+	}
+	if len(docs) == 0 {
+		return pipeline.Context{}, nil
+	}
+
+	outputs := report.Outputs()
+	ctx := pipeline.Context{Documents: docs, Outputs: outputs}
+	if err := pipeline.Run(pipeline.PostConvert, ctx); err != nil {
+		return ctx, err
+	}
+
+	if err := policy.VerifySensitive(outputs); err != nil {
+		return ctx, err
+	}
+	paths := append(append([]string{}, outputs...), convert.MediaFiles(outputs)...)
+	paths = append(paths, restage...)
+	if err := git.Add(paths...); err != nil {
+		return ctx, err
+	}
+	return ctx, nil
+}
+
+// digests 返回文件内容的 sha256，读取失败的文件不在结果中
+func digests(paths []string) map[string]string {
+	result := make(map[string]string, len(paths))
+	for _, p := range paths {
+		if sum := digest(p); sum != "" {
+			result[p] = sum
+		}
+	}
+	return result
+}
+
+// digest 返回文件内容的 sha256，读取失败时返回空字符串
+func digest(p string) string {
+	f, err := os.Open(p)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// commitMsg 按配置的模板改写提交信息，已经符合模板的提交信息（如 amend）不会重复改写
+func commitMsg(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("读取提交信息失败: %v", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	msg := strings.TrimSpace(strings.Join(lines, "\n"))
+	if msg == "" {
+		return fmt.Errorf("commit信息不能为空")
+	}
+
+	// 用占位符渲染模板，得到模板在提交信息前后添加的内容
+	const placeholder = "\x00"
+	wrapped, err := commit.ApplyTemplate(placeholder)
+	if err != nil || wrapped == placeholder {
+		return err
+	}
+	prefix, suffix, _ := strings.Cut(wrapped, placeholder)
+	if strings.HasPrefix(msg, strings.TrimSpace(prefix)) && strings.HasSuffix(msg, strings.TrimSpace(suffix)) {
+		return nil
+	}
+	msg, err = commit.ApplyTemplate(msg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, []byte(msg+"\n"), 0644); err != nil {
+		return fmt.Errorf("写入提交信息失败: %v", err)
+	}
+	return nil
+}
+
+// prePush 检查推送的提交中，每个变更的文档都有对应的 markdown；runHooks 为 true 时按 policy 配置检查推送的提交，
+// 并执行配置的 hooks.pre_push
+func prePush(stdin io.Reader, runHooks bool) error {
+	var missing []string
+	// ranges 推送的每个分支对应的提交范围，依次为 remote sha 与 local sha
+	var ranges [][2]string
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		// 格式: <local ref> <local sha> <remote ref> <remote sha>
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[1] == zeroSha {
+			continue
+		}
+		local, remote := fields[1], fields[3]
+		// 推送新分支，或远端分支已被他人更新、本地尚未 fetch 时，与已知的远端分支比较，是否能快进交给 git push 报告
+		if remote == zeroSha || !git.HasObject(remote) {
+			remote = ""
+		}
+		ranges = append(ranges, [2]string{remote, local})
+		changed, err := git.ChangedFiles(remote, local)
+		if err != nil {
+			return err
+		}
+		for _, doc := range documents(changed) {
+			if !git.Exists(local, convert.OutputPath(doc)) {
+				missing = append(missing, doc)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取推送信息失败: %v", err)
+	}
+	if len(missing) == 0 {
+		if !runHooks {
+			return nil
+		}
+		for _, r := range ranges {
+			if err := policy.VerifyRevision(r[0], r[1]); err != nil {
+				return err
+			}
+			if err := locks.VerifyRevision(r[0], r[1]); err != nil {
+				return err
+			}
+		}
+		return pipeline.Run(pipeline.PrePush, pipeline.Context{})
+	}
+	for _, doc := range missing {
+		log.Error("文档 %s 没有提交对应的 %s", doc, convert.OutputPath(doc))
+	}
+	return fmt.Errorf("有 %d 个文档没有提交转换后的 markdown，请执行 gitdoc-cli commit 后再推送", len(missing))
+}
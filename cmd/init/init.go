@@ -2,21 +2,31 @@ package init
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/cmd/filter"
+	lfs_cmd "github.com/zhihanggg/gitdoc-cli/cmd/lfs"
+	"github.com/zhihanggg/gitdoc-cli/git"
 	"github.com/zhihanggg/gitdoc-cli/log"
 	"github.com/zhihanggg/gitdoc-cli/utils"
 )
 
 func NewCmd() *cobra.Command {
 	impl := initImpl{}
-	return &cobra.Command{
+	initCmd := &cobra.Command{
 		Use:   "init",
 		Short: "init 命令用来初始化环境,安装一些依赖",
-		Long:  "init 命令用来初始化环境,安装一些依赖",
-		RunE:  impl.run(),
+		Long: "init 命令用来初始化环境,安装一些依赖；添加 --filter 时会注册 docx 的 clean/smudge filter，并在当前仓库的 .gitattributes 中启用；" +
+			"添加 --lfs 时会在当前仓库中以 Git LFS 存储文档与媒体文件",
+		RunE: impl.run(),
 	}
+	initCmd.Flags().Bool("filter", false, "注册 docx 的 clean/smudge filter，使 docx 以不压缩的形式存储")
+	initCmd.Flags().Bool("lfs", false, "以 Git LFS 存储 docx/pptx/xlsx 与导出的媒体文件")
+	return initCmd
 }
 
 type initImpl struct {
@@ -24,6 +34,12 @@ type initImpl struct {
 
 func (i *initImpl) run() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		prefix := utils.GetParamPrefix(cmd)
+		// LFS 与 filter 都通过 .gitattributes 的 filter 属性生效，同一个文件只能使用其中一个
+		if viper.GetBool(prefix+"filter") && viper.GetBool(prefix+"lfs") {
+			return fmt.Errorf("--filter 与 --lfs 不能同时使用")
+		}
+
 		// 检查git安装情况
 		if err := CheckAndInstallGit(); err != nil {
 			return err
@@ -39,8 +55,52 @@ func (i *initImpl) run() func(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		// 注册 docx 的 clean/smudge filter
+		if viper.GetBool(prefix + "filter") {
+			if err := SetupFilter(); err != nil {
+				return err
+			}
+		}
+
+		// 使用 Git LFS 存储文档与媒体文件
+		if viper.GetBool(prefix + "lfs") {
+			if err := lfs_cmd.Setup(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// SetupFilter 在全局 git 配置中注册 filter；当前目录是 git 仓库时同时在 .gitattributes 中启用
+func SetupFilter() error {
+	log.Info("开始配置 docx filter...")
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取 gitdoc-cli 路径失败: %v", err)
+	}
+	if err := filter.Setup(binary); err != nil {
+		return err
+	}
+	log.Info("docx filter 已注册")
+
+	root, err := git.TopLevel()
+	if err != nil {
+		log.Warn("当前目录不是 git 仓库，请在仓库中重新执行 gitdoc-cli init --filter 以启用 filter")
 		return nil
 	}
+	added, err := filter.AddAttributes(filepath.Join(root, ".gitattributes"))
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		log.Info(".gitattributes 中已启用 docx filter")
+		return nil
+	}
+	log.Info("已在 .gitattributes 中添加: %s", strings.Join(added, ", "))
+	log.Info("已提交的 docx 可以执行 git add --renormalize . 后提交，改为不压缩的形式存储")
+	return nil
 }
 
 // CheckAndSetupGitConfig 检测是否设置git用户信息，如果没有则提示用户设置
@@ -51,7 +111,7 @@ func CheckAndSetupGitConfig() error {
 	userName, err := utils.ExecCmd("git config --global user.name")
 	if err != nil || strings.TrimSpace(userName) == "" {
 		log.Warn("未检测到git user.name配置")
-		log.Info("请输入您的git用户名: ")
+		log.Prompt("请输入您的git用户名: ")
 		var inputName string
 		fmt.Scanln(&inputName)
 
@@ -72,7 +132,7 @@ func CheckAndSetupGitConfig() error {
 	userEmail, err := utils.ExecCmd("git config --global user.email")
 	if err != nil || strings.TrimSpace(userEmail) == "" {
 		log.Warn("未检测到git user.email配置")
-		log.Info("请输入您的git邮箱: ")
+		log.Prompt("请输入您的git邮箱: ")
 		var inputEmail string
 		fmt.Scanln(&inputEmail)
 
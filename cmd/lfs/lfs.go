@@ -0,0 +1,175 @@
+package lfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/githooks"
+	"github.com/zhihanggg/gitdoc-cli/lfs"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// NewCmd 返回 lfs 相关子命令
+func NewCmd() *cobra.Command {
+	impl := lfsImpl{}
+	lfsCmd := &cobra.Command{
+		Use:   "lfs",
+		Short: "lfs 命令用来以 Git LFS 存储 docx/pptx/xlsx 与导出的媒体文件",
+		Long:  "lfs 命令用来以 Git LFS 存储 docx/pptx/xlsx 与导出的媒体文件，避免仓库随文档的每次修改快速膨胀",
+	}
+	lfsCmd.AddCommand(&cobra.Command{
+		Use:   "track",
+		Short: "安装 Git LFS 并在 .gitattributes 中跟踪文档与媒体文件",
+		Args:  cobra.NoArgs,
+		RunE:  impl.track(),
+	})
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "将历史提交中的文档与媒体文件改为 LFS 存储，会改写提交历史",
+		Long:  "migrate 命令将历史提交中的文档与媒体文件改为 LFS 存储；会改写提交历史，已推送的分支需要强制推送，其他人需要重新克隆",
+		Args:  cobra.NoArgs,
+		RunE:  impl.migrate(),
+	}
+	migrateCmd.Flags().Bool("everything", false, "改写全部本地分支与标签，默认只改写当前分支")
+	migrateCmd.Flags().BoolP("yes", "y", false, "不再确认，直接改写提交历史")
+	lfsCmd.AddCommand(migrateCmd)
+	return lfsCmd
+}
+
+type lfsImpl struct {
+}
+
+func (i *lfsImpl) track() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return Setup()
+	}
+}
+
+func (i *lfsImpl) migrate() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if _, err := lfs.Version(); err != nil {
+			return err
+		}
+		prefix := utils.GetParamPrefix(cmd)
+		patterns, err := migratePatterns()
+		if err != nil {
+			return err
+		}
+		scope := "当前分支"
+		if viper.GetBool(prefix + "everything") {
+			scope = "全部本地分支与标签"
+		}
+		log.Warn("即将改写%s的提交历史，将 %s 改为 LFS 存储；完成后需要强制推送，其他人需要重新克隆", scope, strings.Join(patterns, ", "))
+		if !viper.GetBool(prefix + "yes") {
+			log.Prompt("确认继续? (y/N): ")
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				return fmt.Errorf("已取消")
+			}
+		}
+		if err := lfs.Migrate(patterns, viper.GetBool(prefix+"everything")); err != nil {
+			return err
+		}
+		log.Info("迁移完成，请执行 git push --force-with-lease 推送改写后的提交")
+		return nil
+	}
+}
+
+// migratePatterns 返回需要迁移的路径模式：.gitattributes 中已使用 LFS 存储的路径模式；
+// git lfs migrate import 会为迁移的路径模式追加 filter=lfs，已设置其他 filter（如 gitdoc）的路径模式不能迁移
+func migratePatterns() ([]string, error) {
+	root, err := git.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	filters, err := lfs.Filters(root)
+	if err != nil {
+		return nil, err
+	}
+	bound := make(map[string]string, len(filters))
+	for _, f := range filters {
+		bound[f.Pattern] = f.Filter
+	}
+	for _, pattern := range lfs.Patterns() {
+		if filter := bound[pattern]; filter != "" && filter != "lfs" {
+			log.Warn(".gitattributes 中 %s 已使用 filter=%s，不会迁移到 LFS 存储", pattern, filter)
+		}
+	}
+	patterns, err := lfs.Tracked(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf(".gitattributes 中没有使用 LFS 存储的文件，请先执行 gitdoc-cli lfs track")
+	}
+	return patterns, nil
+}
+
+// Setup 安装 Git LFS 并在当前仓库的 .gitattributes 中跟踪文档与媒体文件；
+// 已安装的 gitdoc-cli hooks 会重新安装，以串联 LFS 的 hooks
+func Setup() error {
+	log.Info("开始配置 Git LFS...")
+	version, err := lfs.Version()
+	if err != nil {
+		return err
+	}
+	log.Debug("%s", version)
+	root, err := git.TopLevel()
+	if err != nil {
+		return err
+	}
+
+	// git lfs install 遇到已存在的 hooks 会失败，先卸载 gitdoc-cli 的 hooks，安装后再串联
+	statuses, err := githooks.List()
+	if err != nil {
+		return err
+	}
+	reinstall := false
+	for _, s := range statuses {
+		if s.State == githooks.Installed {
+			reinstall = true
+		}
+	}
+	if reinstall {
+		if _, err := githooks.Uninstall(); err != nil {
+			return err
+		}
+	}
+	installErr := lfs.Install()
+	if reinstall {
+		binary, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("获取 gitdoc-cli 路径失败: %v", err)
+		}
+		if _, err := githooks.Install(binary); err != nil {
+			return err
+		}
+	}
+	if installErr != nil {
+		return installErr
+	}
+
+	added, skipped, err := lfs.Track(root, lfs.Patterns())
+	for _, f := range skipped {
+		log.Warn(".gitattributes 中 %s 已使用 filter=%s，未改为 LFS 存储", f.Pattern, f.Filter)
+	}
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 && len(skipped) == 0 {
+		log.Info("Git LFS 已跟踪全部文档与媒体文件")
+		return nil
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	log.Info("已在 .gitattributes 中使用 LFS 跟踪: %s", strings.Join(added, ", "))
+	log.Info("已提交的历史可以执行 gitdoc-cli lfs migrate 改为 LFS 存储")
+	return nil
+}
@@ -0,0 +1,109 @@
+package lock
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/locks"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// NewCmd 返回 lock 命令
+func NewCmd() *cobra.Command {
+	impl := lockImpl{}
+	return &cobra.Command{
+		Use:   "lock <doc>...",
+		Short: "lock 命令用来锁定文档，避免多人同时修改无法合并的文档",
+		Long:  "lock 命令用来锁定文档，其他人提交或推送被锁定文档的修改时会收到警告或被拒绝；锁按 lock.backend 配置记录在 .gitdoc/locks.yml 或 Git LFS 中",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  impl.lock(),
+	}
+}
+
+// NewUnlockCmd 返回 unlock 命令
+func NewUnlockCmd() *cobra.Command {
+	impl := lockImpl{}
+	unlockCmd := &cobra.Command{
+		Use:   "unlock <doc>...",
+		Short: "unlock 命令用来解锁文档",
+		Long:  "unlock 命令用来解锁自己锁定的文档，添加 --force 可以解除其他人的锁",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  impl.unlock(),
+	}
+	unlockCmd.Flags().Bool("force", false, "强制解除其他人的锁")
+	return unlockCmd
+}
+
+// NewLocksCmd 返回 locks 命令
+func NewLocksCmd() *cobra.Command {
+	impl := lockImpl{}
+	return &cobra.Command{
+		Use:   "locks",
+		Short: "locks 命令用来查看全部文档锁",
+		Long:  "locks 命令用来查看全部文档锁及其持有者",
+		Args:  cobra.NoArgs,
+		RunE:  impl.list(),
+	}
+}
+
+type lockImpl struct {
+}
+
+func (i *lockImpl) lock() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		backend := locks.New()
+		for _, arg := range args {
+			p, err := locks.Normalize(arg)
+			if err != nil {
+				return err
+			}
+			l, err := backend.Lock(p)
+			if err != nil {
+				return err
+			}
+			log.Info("已锁定 %s: %s", p, l.Describe())
+		}
+		return nil
+	}
+}
+
+func (i *lockImpl) unlock() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		force := viper.GetBool(utils.GetParamPrefix(cmd) + "force")
+		backend := locks.New()
+		for _, arg := range args {
+			p, err := locks.Normalize(arg)
+			if err != nil {
+				return err
+			}
+			if err := backend.Unlock(p, force); err != nil {
+				return err
+			}
+			log.Info("已解锁 %s", p)
+		}
+		return nil
+	}
+}
+
+func (i *lockImpl) list() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		all, err := locks.New().List()
+		if err != nil {
+			return fmt.Errorf("获取文档锁失败: %v", err)
+		}
+		if len(all) == 0 {
+			log.Info("没有被锁定的文档")
+			return nil
+		}
+		for _, l := range all {
+			mark := ""
+			if l.Ours {
+				mark = "（自己）"
+			}
+			log.Normal("%s\t%s%s", l.Path, l.Describe(), mark)
+		}
+		return nil
+	}
+}
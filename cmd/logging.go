@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+	"gopkg.in/op/go-logging.v1"
+)
+
+// logFile --log-file 打开的日志文件
+var logFile *os.File
+
+// setLogLevel 按 --quiet、-v/-vv 与 --trace 设置终端输出的日志级别，--log-file 指定文件时将包括 trace 在内的全部日志
+// 以 json 格式追加到该文件；go-logging 的日志同样交给 log 输出
+func setLogLevel(cmd *cobra.Command) error {
+	prefix := utils.GetParamPrefix(cmd)
+	quiet := viper.GetBool(prefix + "quiet")
+	verbose := viper.GetInt(prefix + "verbose")
+	if quiet && verbose > 0 {
+		return fmt.Errorf("--quiet 与 --verbose 不能同时使用")
+	}
+	// 默认只输出 Info 及以上级别，-v 输出 Debug，-vv 与 --trace 同时输出 Trace
+	p := log.DefaultStd.WithLevel(log.LevelInfo)
+	switch {
+	case quiet:
+		p = p.WithLevel(log.LevelWarn)
+	case verbose > 0:
+		p = p.WithLevel(log.LevelDebug)
+	default:
+	}
+	if verbose > 1 || viper.GetBool(prefix+"trace") {
+		p = p.WithEnableTrace().WithLevel(log.LevelDebug)
+	}
+	log.DefaultStd = &p
+	// 级别由 log 控制，go-logging 不再过滤
+	backend := logging.SetBackend(logBackend{})
+	backend.SetLevel(logging.DEBUG, "")
+
+	file := viper.GetString(prefix + "log-file")
+	if file == "" || logFile != nil {
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件 %s 失败: %v", file, err)
+	}
+	logFile = f
+	log.AddSink(log.Sink{Writer: f, Format: log.FormatJSON, Trace: true})
+	return nil
+}
+
+// closeLogFile 命令执行结束后关闭日志文件
+func closeLogFile() {
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+}
+
+// logBackend 将 go-logging 的日志转交给 log 输出
+type logBackend struct {
+}
+
+// Log 实现 logging.Backend，calldepth 为 go-logging 调用方相对本函数的深度
+func (b logBackend) Log(level logging.Level, calldepth int, rec *logging.Record) error {
+	p := log.DefaultStd.WithAddCallDepth(calldepth)
+	if rec.Module != "" {
+		p = p.WithPrefix(rec.Module)
+	}
+	msg := rec.Message()
+	switch level {
+	case logging.CRITICAL, logging.ERROR:
+		p.Error("%s", msg)
+	case logging.WARNING:
+		p.Warn("%s", msg)
+	case logging.NOTICE, logging.INFO:
+		p.Info("%s", msg)
+	default:
+		p.Trace("%s", msg)
+	}
+	return nil
+}
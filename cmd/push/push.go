@@ -1,21 +1,36 @@
 package push
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/locks"
 	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/pipeline"
+	"github.com/zhihanggg/gitdoc-cli/policy"
 	"github.com/zhihanggg/gitdoc-cli/utils"
 )
 
 func NewCmd() *cobra.Command {
 	impl := pushImpl{}
-	return &cobra.Command{
+	pushCmd := &cobra.Command{
 		Use:   "push",
 		Short: "push 命令用来推送变更到远端",
-		Long:  "push 命令用来推送变更到远端",
+		Long:  "push 命令用来推送变更到远端，推送前按 policy 配置检查待推送提交中变更的文档，并检查是否修改了其他人锁定的文档",
 		RunE:  impl.run(),
 	}
+	pushCmd.Flags().Bool("no-verify", false, "跳过 policy 配置的提交规则检查、文档锁检查与 pre-push hook")
+	pushCmd.Flags().Bool("dry-run", false, "只列出将要推送的分支与提交，不连接远端")
+	return pushCmd
 }
 
 type pushImpl struct {
@@ -23,14 +38,129 @@ type pushImpl struct {
 
 func (i *pushImpl) run() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		noVerify := viper.GetBool(utils.GetParamPrefix(cmd) + "no-verify")
+		if !noVerify {
+			if err := policy.VerifyRevision(pushBase(), "HEAD"); err != nil {
+				return err
+			}
+			if err := locks.VerifyRevision(pushBase(), "HEAD"); err != nil {
+				return err
+			}
+		}
+		if viper.GetBool(utils.GetParamPrefix(cmd) + "dry-run") {
+			return dryRun()
+		}
+		if err := pipeline.Run(pipeline.PrePush, pipeline.Context{}); err != nil {
+			return err
+		}
+
 		log.Debug("开始执行 git push...")
-		output, err := utils.ExecCmd("git push")
+		output, err := gitPush(noVerify)
 		if err != nil {
 			return fmt.Errorf("git push 失败: %v", err)
 		}
 
 		log.Info("git push 成功执行")
 		log.Debug("git push 输出: %s", output)
-		return nil
+		return pipeline.Run(pipeline.PostPush, pipeline.Context{})
+	}
+}
+
+// objectsProgressRE git push 传输对象时输出的进度，出现时鉴权与 pre-push hook 都已完成
+var objectsProgressRE = regexp.MustCompile(`^(Enumerating|Counting|Compressing|Writing) objects:`)
+
+// gitPush 执行 git push --progress 并返回其输出，将 git 传输对象的进度显示在进度条上；
+// git 可能先在终端上询问账号密码或 SSH 密钥口令，进度条在 git 开始传输对象后才显示，不会刷新最后一行覆盖提示
+func gitPush(noVerify bool) (string, error) {
+	args := []string{"push", "--progress"}
+	if noVerify {
+		args = append(args, "--no-verify")
+	}
+	cmd := exec.Command("git", args...)
+	// 标记由 gitdoc-cli 发起，pre-push hook 不会重复执行 hooks.pre_push 与规则检查
+	cmd.Env = append(os.Environ(), constant.EnvInternal+"=1")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	var bar *log.Progress
+	scanner := bufio.NewScanner(stderr)
+	// 进度以 \r 分隔，同一行不断刷新
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	var messages strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if bar == nil && objectsProgressRE.MatchString(line) {
+			bar = log.NewProgress("git push", 0)
+		}
+		if bar != nil {
+			bar.Start(line)
+		}
+		// 刷新中的进度只保留完成时的一行
+		if !strings.Contains(line, "%") || strings.HasSuffix(line, "done.") {
+			messages.WriteString(line + "\n")
+		}
+	}
+	err = cmd.Wait()
+	if bar != nil {
+		bar.Finish()
+	}
+	output := stdout.String() + messages.String()
+	if err != nil {
+		return "", fmt.Errorf("%v, 输出: %s", err, output)
+	}
+	return output, nil
+}
+
+// pushBase 返回检查待推送提交时比较的基准：上游分支，未设置上游分支（如新分支）时为 HEAD 中已推送到远端的最新提交
+func pushBase() string {
+	if upstream := git.Upstream(); upstream != "" {
+		return upstream
+	}
+	return git.RemoteBase("HEAD")
+}
+
+// dryRun 列出将要推送的分支与提交，只读取本地记录的上游分支，不连接远端
+func dryRun() error {
+	branch := git.Branch()
+	if branch == "" {
+		return fmt.Errorf("当前不在任何分支上，无法推送")
+	}
+	upstream := git.UpstreamName()
+	if upstream == "" {
+		log.Normal("分支 %s 未设置上游分支，git push 将按 push.default 配置推送", branch)
+	} else {
+		log.Normal("将推送 %s -> %s", branch, upstream)
+	}
+	commits, err := git.Log(pushBase(), "HEAD")
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		log.Normal("没有需要推送的提交")
+	} else {
+		log.Normal("将推送 %d 个提交:", len(commits))
+		for _, c := range commits {
+			log.Normal("  %s", c)
+		}
 	}
+	log.Info("试运行，未连接远端")
+	return nil
 }
@@ -2,8 +2,11 @@ package state
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/lfs"
 	"github.com/zhihanggg/gitdoc-cli/log"
 	"github.com/zhihanggg/gitdoc-cli/utils"
 )
@@ -50,6 +53,35 @@ func (i *stateImpl) run() func(cmd *cobra.Command, args []string) error {
 		}
 		log.Info("当前本地分支: %s", branchOutput)
 
+		// 检查 Git LFS，有问题时不影响查看其他状态
+		if err := CheckLFS(); err != nil {
+			log.Error("%v", err)
+		}
+
 		return nil
 	}
 }
+
+// CheckLFS 检查 Git LFS 的安装情况，仓库使用了 LFS 但未安装时返回错误
+func CheckLFS() error {
+	root, err := git.TopLevel()
+	if err != nil {
+		return err
+	}
+	tracked, err := lfs.Tracked(root)
+	if err != nil {
+		return err
+	}
+	version, err := lfs.Version()
+	switch {
+	case err != nil && len(tracked) > 0:
+		return fmt.Errorf("仓库使用 Git LFS 存储 %s，但未安装 Git LFS，检出的文档将无法打开: %v", strings.Join(tracked, ", "), err)
+	case err != nil:
+		log.Info("未安装 Git LFS，仓库未使用 LFS")
+	case len(tracked) > 0:
+		log.Info("%s，LFS 跟踪: %s", version, strings.Join(tracked, ", "))
+	default:
+		log.Info("%s，仓库未使用 LFS，可以执行 gitdoc-cli lfs track 启用", version)
+	}
+	return nil
+}
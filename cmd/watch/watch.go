@@ -0,0 +1,288 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/cmd/commit"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/locks"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/pipeline"
+	"github.com/zhihanggg/gitdoc-cli/policy"
+	"github.com/zhihanggg/gitdoc-cli/scan"
+)
+
+// NewCmd 返回 watch 相关子命令
+func NewCmd() *cobra.Command {
+	impl := watchImpl{}
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "watch 命令用来监听文档变化，自动转换为markdown",
+		Long:  "watch 命令用来监听文档变化，文档保存后自动转换为markdown，可以开启定时自动提交",
+		RunE:  impl.run(),
+	}
+	watchCmd.Flags().Duration("debounce", 3*time.Second, "文档保存后等待多久再转换")
+	watchCmd.Flags().Bool("auto-commit", false, "是否定时自动提交转换结果")
+	watchCmd.Flags().Duration("commit-interval", 10*time.Minute, "自动提交的间隔")
+	return watchCmd
+}
+
+type watchImpl struct {
+	opts    scan.Options
+	watcher *fsnotify.Watcher
+	// timers 等待转换的文档
+	timers map[string]*time.Timer
+	// ready 等待时间结束、可以转换的文档
+	ready chan string
+	// pending 已转换、尚未自动提交的文档及其生成的文件
+	pending map[string][]string
+	// moved 文档重命名时移动的生成文件的原路径，自动提交时一并提交其删除
+	moved []string
+}
+
+func (i *watchImpl) run() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		// 配置项名称使用下划线，与命令行参数名称不同，需要单独绑定，指定命令行参数时优先使用
+		_ = viper.BindPFlag(config.KeyWatchAutoCommit, cmd.Flags().Lookup("auto-commit"))
+		_ = viper.BindPFlag(config.KeyWatchCommitInterval, cmd.Flags().Lookup("commit-interval"))
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("创建文件监听失败: %v", err)
+		}
+		defer watcher.Close()
+
+		i.opts = scan.OptionsFromConfig()
+		i.watcher = watcher
+		i.timers = make(map[string]*time.Timer)
+		i.ready = make(chan string, 64)
+		i.pending = make(map[string][]string)
+		if err := i.addDirs(i.opts); err != nil {
+			return err
+		}
+
+		debounce := viper.GetDuration(config.KeyWatchDebounce)
+		autoCommit := viper.GetBool(config.KeyWatchAutoCommit)
+		var tick <-chan time.Time
+		if autoCommit {
+			ticker := time.NewTicker(viper.GetDuration(config.KeyWatchCommitInterval))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+		log.Info("开始监听文档变化，按 Ctrl+C 退出")
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				i.handle(event, debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				log.Warn("文件监听出错: %v", err)
+			case doc := <-i.ready:
+				i.convert(doc, debounce)
+			case <-tick:
+				i.commit()
+			case <-signals:
+				if autoCommit {
+					i.commit()
+				}
+				log.Info("已停止监听")
+				return nil
+			}
+		}
+	}
+}
+
+// addDirs 监听扫描范围内全部未被忽略的目录
+func (i *watchImpl) addDirs(opts scan.Options) error {
+	dirs, err := scan.Dirs(opts)
+	if err != nil {
+		return fmt.Errorf("扫描目录失败: %v", err)
+	}
+	for _, dir := range dirs {
+		if err := i.watcher.Add(dir); err != nil {
+			return fmt.Errorf("监听目录 %s 失败: %v", dir, err)
+		}
+	}
+	return nil
+}
+
+// handle 处理文件变化，文档在 debounce 时间内没有新的变化才会转换
+func (i *watchImpl) handle(event fsnotify.Event, debounce time.Duration) {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			opts := i.opts
+			opts.Roots = []string{event.Name}
+			if err := i.addDirs(opts); err != nil {
+				log.Warn("%v", err)
+			}
+			return
+		}
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) {
+		return
+	}
+	doc := event.Name
+	if _, ok := convert.Lookup(doc); !ok || scan.Ignored(i.opts, doc) {
+		return
+	}
+	i.schedule(doc, debounce)
+}
+
+// schedule 在 delay 后将文档放入待转换队列，重复调用会重新计时
+func (i *watchImpl) schedule(doc string, delay time.Duration) {
+	if timer, ok := i.timers[doc]; ok {
+		timer.Reset(delay)
+		return
+	}
+	i.timers[doc] = time.AfterFunc(delay, func() {
+		i.ready <- doc
+	})
+}
+
+// convert 转换文档，仓库被其他命令占用时稍后重试
+func (i *watchImpl) convert(doc string, retry time.Duration) {
+	delete(i.timers, doc)
+	if _, err := os.Stat(doc); err != nil {
+		// Word 保存时会先重命名原文件，文件不存在说明已被删除或重命名
+		return
+	}
+	lock, err := git.AcquireLock()
+	if errors.Is(err, git.ErrLocked) {
+		log.Debug("仓库正在被其他命令操作，稍后重新转换 %s", doc)
+		i.schedule(doc, retry)
+		return
+	}
+	if err != nil {
+		log.Error("%v", err)
+		return
+	}
+	defer lock.Release()
+
+	renames, err := convert.MoveRenamed([]string{doc})
+	if err != nil {
+		log.Error("%v", err)
+		return
+	}
+	for _, r := range renames {
+		// 自动提交时一并提交原文档的删除，未提交过的原文档不需要提交
+		if git.Tracked(r.From) {
+			i.pending[r.From] = nil
+		}
+		i.moved = append(i.moved, r.Moved...)
+	}
+	if err := pipeline.Run(pipeline.PreConvert, pipeline.Context{Documents: []string{doc}}); err != nil {
+		log.Error("%v", err)
+		return
+	}
+	log.Debug("正在转换: %s -> %s", doc, convert.OutputPath(doc))
+	outputs, err := convert.File(doc)
+	if err != nil {
+		log.Error("转换文件 %s 失败: %v", doc, err)
+		return
+	}
+	if err := pipeline.Run(pipeline.PostConvert, pipeline.Context{Documents: []string{doc}, Outputs: outputs}); err != nil {
+		log.Error("%v", err)
+		return
+	}
+	i.pending[doc] = outputs
+	log.Info("已转换 %s", doc)
+}
+
+// commit 自动提交已转换的文档
+func (i *watchImpl) commit() {
+	if len(i.pending) == 0 {
+		return
+	}
+	lock, err := git.AcquireLock()
+	if err != nil {
+		log.Debug("暂不自动提交: %v", err)
+		return
+	}
+	defer lock.Release()
+
+	docs := make([]string, 0, len(i.pending))
+	var outputs []string
+	for doc, files := range i.pending {
+		docs = append(docs, doc)
+		outputs = append(outputs, files...)
+	}
+	sort.Strings(docs)
+	sort.Strings(outputs)
+	paths := append(append([]string{}, docs...), outputs...)
+	paths = append(paths, convert.MediaFiles(outputs)...)
+	// 移动的生成文件已由 git mv 暂存，只需一并提交
+	committed := append(append([]string{}, paths...), i.moved...)
+
+	if err := policy.VerifySensitive(outputs); err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	if err := git.Add(paths...); err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	if !git.HasStaged(committed...) {
+		i.pending = make(map[string][]string)
+		i.moved = nil
+		return
+	}
+	if err := policy.VerifyStaged(); err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	if err := locks.VerifyStaged(); err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	msg, err := commit.ApplyTemplate(message(docs))
+	if err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	ctx := pipeline.Context{Documents: docs, Outputs: outputs, Message: msg}
+	if err := pipeline.Run(pipeline.PreCommit, ctx); err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	// 只提交本次转换的文档与生成的文件，不包括用户自行暂存的其他变更
+	if err := git.Commit(msg, committed...); err != nil {
+		log.Error("自动提交失败: %v", err)
+		return
+	}
+	i.pending = make(map[string][]string)
+	i.moved = nil
+	log.Info("已自动提交 %d 个文档", len(docs))
+	if err := pipeline.Run(pipeline.PostCommit, ctx); err != nil {
+		log.Error("%v", err)
+	}
+}
+
+// message 生成自动提交的 commit 信息
+func message(docs []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "自动提交: 更新 %d 个文档\n\n", len(docs))
+	for _, doc := range docs {
+		sb.WriteString("- " + doc + "\n")
+	}
+	return sb.String()
+}
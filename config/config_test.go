@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	values := map[string]interface{}{
+		"converter": map[string]interface{}{
+			"format": "gfm",
+			"wrap":   "bad",
+			"fromat": "gfm",
+		},
+		"scan":   map[string]interface{}{"ignore": []interface{}{"*.tmp", 1}},
+		"create": map[string]interface{}{"project-name": "demo"},
+	}
+	errs := Validate(values, []string{"create.project-name"})
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), "converter.format")
+	assert.Contains(t, errs[1].Error(), "converter.wrap")
+	assert.Contains(t, errs[2].Error(), "scan.ignore")
+}
+
+func TestValidateRules(t *testing.T) {
+	key, _ := Lookup(KeyConverterRules)
+	value, err := ParseValue(key, `[{match: "contracts/**", format: gfm, filters: [a.lua], track_changes: all}]`)
+	assert.Nil(t, err)
+	assert.Len(t, value, 1)
+
+	cases := map[string]string{
+		`[{format: gfm}]`:                       "缺少 match",
+		`[{match: "*.docx", fromat: gfm}]`:      "是否想设置 format",
+		`[{match: "*.docx", track_changes: x}]`: "track_changes",
+		`[a.docx]`:                              "应为对象",
+	}
+	for raw, msg := range cases {
+		_, err := ParseValue(key, raw)
+		assert.NotNil(t, err, raw)
+		assert.Contains(t, err.Error(), msg, raw)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	key, ok := Lookup(KeyScanIgnore)
+	assert.True(t, ok)
+	value, err := ParseValue(key, "a, *.tmp,")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []interface{}{"a", "*.tmp"}, value)
+
+	key, _ = Lookup(KeyConverterFormat)
+	_, err = ParseValue(key, "html")
+	assert.NotNil(t, err)
+}
+
+func TestSuggest(t *testing.T) {
+	assert.EqualValues(t, "converter.format", Suggest("converter.fromat"))
+	assert.EqualValues(t, "converter", Suggest("convertr"))
+	assert.EqualValues(t, "", Suggest("abcdefg"))
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.Nil(t, os.WriteFile(path, []byte("# 注释\nconverter:\n  wrap: none\n"), 0644))
+
+	file, err := Load(path)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Set(KeyConverterFormat, "gfm"))
+	assert.Nil(t, file.Set(KeyScanIgnore, []interface{}{"*.tmp"}))
+	assert.True(t, file.Unset(KeyConverterWrap))
+	assert.False(t, file.Unset("converter.missing"))
+	assert.Nil(t, file.Save())
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "# 注释\nconverter:\n  format: gfm\nscan:\n  ignore:\n    - '*.tmp'\n", string(content))
+
+	value, ok := file.Get(KeyConverterFormat)
+	assert.True(t, ok)
+	assert.EqualValues(t, "gfm", value)
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File 配置文件，修改时保留原有的注释与顺序
+type File struct {
+	// Path 配置文件路径
+	Path string
+	// root 配置文件的 yaml 文档
+	root *yaml.Node
+}
+
+// Load 读取配置文件，文件不存在时返回空的配置
+func Load(path string) (*File, error) {
+	f := &File{Path: path}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %v", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	if len(doc.Content) > 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("配置文件 %s 的顶层应为 key: value 结构", path)
+		}
+		f.root = &doc
+	}
+	return f, nil
+}
+
+// Values 返回配置文件的全部内容
+func (f *File) Values() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if f.root == nil {
+		return values, nil
+	}
+	if err := f.root.Decode(&values); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", f.Path, err)
+	}
+	return values, nil
+}
+
+// Get 返回配置文件中 name 对应的值
+func (f *File) Get(name string) (interface{}, bool) {
+	node := f.find(name)
+	if node == nil {
+		return nil, false
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set 设置配置项的值，不存在的上级会自动创建
+func (f *File) Set(name string, value interface{}) error {
+	if f.root == nil {
+		f.root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("编码配置项 %s 失败: %v", name, err)
+	}
+
+	node := f.root.Content[0]
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		child := mappingValue(node, part)
+		if i == len(parts)-1 {
+			if child != nil {
+				*child = valueNode
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, &valueNode)
+			}
+			return nil
+		}
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+		}
+		if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("配置项 %s 不是 key: value 结构", strings.Join(parts[:i+1], "."))
+		}
+		node = child
+	}
+	return nil
+}
+
+// Unset 删除配置项，返回配置项是否存在；删除后为空的上级也会一并删除
+func (f *File) Unset(name string) bool {
+	if f.root == nil {
+		return false
+	}
+	return unset(f.root.Content[0], strings.Split(name, "."))
+}
+
+func unset(node *yaml.Node, parts []string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+		child := node.Content[i+1]
+		if child.Kind != yaml.MappingNode || !unset(child, parts[1:]) {
+			return false
+		}
+		if len(child.Content) == 0 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// Save 将配置写回文件
+func (f *File) Save() error {
+	var buf bytes.Buffer
+	if f.root != nil {
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(f.root); err != nil {
+			return fmt.Errorf("编码配置文件失败: %v", err)
+		}
+		_ = encoder.Close()
+	}
+	if err := os.WriteFile(f.Path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入配置文件 %s 失败: %v", f.Path, err)
+	}
+	return nil
+}
+
+// find 查找 name 对应的节点
+func (f *File) find(name string) *yaml.Node {
+	if f.root == nil {
+		return nil
+	}
+	node := f.root.Content[0]
+	for _, part := range strings.Split(name, ".") {
+		if node = mappingValue(node, part); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// mappingValue 返回 mapping 节点中 key 对应的值节点
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func unmarshalYaml(raw string, out interface{}) error {
+	return yaml.Unmarshal([]byte(raw), out)
+}
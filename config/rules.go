@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RuleMatch 转换规则中匹配文档路径的字段，语法与 .gitignore 相同
+const RuleMatch = "match"
+
+// ruleFields 转换规则中可以设置的字段及其对应的全局配置项，字段的取值约束与全局配置项相同
+var ruleFields = map[string]string{
+	"format":        KeyConverterFormat,
+	"wrap":          KeyConverterWrap,
+	"filters":       KeyConverterFilters,
+	"track_changes": KeyConverterTrackChanges,
+	"extract_media": KeyConverterExtractMedia,
+	"comments":      KeyConverterComments,
+}
+
+// validateRules 需要查询 schema，在 init 中设置以避免初始化循环
+func init() {
+	for i := range schema {
+		if schema[i].Name == KeyConverterRules {
+			schema[i].Validate = validateRules
+		}
+	}
+}
+
+// validateRules 校验 converter.rules 的每条规则
+func validateRules(value interface{}) error {
+	for i, item := range value.([]interface{}) {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("第 %d 条规则应为对象，实际为 %v", i+1, item)
+		}
+		if match, _ := rule[RuleMatch].(string); strings.TrimSpace(match) == "" {
+			return fmt.Errorf("第 %d 条规则缺少 %s", i+1, RuleMatch)
+		}
+		fields := make([]string, 0, len(rule))
+		for field := range rule {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if field == RuleMatch {
+				continue
+			}
+			name, ok := ruleFields[field]
+			if !ok {
+				return fmt.Errorf("第 %d 条规则的字段 %s 不存在%s", i+1, field, suggestField(field))
+			}
+			key, _ := Lookup(name)
+			if err := CheckValue(key, rule[field]); err != nil {
+				return fmt.Errorf("第 %d 条规则的字段 %s 不合法: %v", i+1, field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// suggestField 返回与 field 最相近的规则字段提示
+func suggestField(field string) string {
+	best, bestDistance := "", len(field)/2+1
+	for candidate := range ruleFields {
+		if d := distance(field, candidate); d < bestDistance || d == bestDistance && candidate < best {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return "，是否想设置 " + best
+}
@@ -0,0 +1,592 @@
+// Package config gitdoc-cli 配置项的定义、读取与校验
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/markdown"
+	"github.com/zhihanggg/gitdoc-cli/sensitive"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// Kind 配置项的值类型
+type Kind int
+
+const (
+	// KindString 字符串
+	KindString Kind = iota
+	// KindBool 布尔值
+	KindBool
+	// KindInt 整数
+	KindInt
+	// KindStrings 字符串列表
+	KindStrings
+	// KindDuration 时长，如 3s、10m
+	KindDuration
+	// KindList 对象列表，由 Key.Validate 校验每个元素
+	KindList
+)
+
+// String 返回类型名称
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindStrings:
+		return "[]string"
+	case KindDuration:
+		return "duration"
+	case KindList:
+		return "list"
+	default:
+	}
+	return "unknown"
+}
+
+// Key 配置项定义
+type Key struct {
+	// Name 配置项名称，以 . 分隔层级，如 converter.format
+	Name string
+	// Kind 值类型
+	Kind Kind
+	// Default 默认值，nil 表示没有默认值
+	Default interface{}
+	// Enum 可选值，仅对 KindString 生效
+	Enum []string
+	// Usage 配置项说明
+	Usage string
+	// Validate 额外的校验逻辑，入参为 yaml 解析后的值
+	Validate func(value interface{}) error
+}
+
+// 配置项名称
+const (
+	// KeyConverterFormat pandoc 输出的 markdown 格式
+	KeyConverterFormat = "converter.format"
+	// KeyConverterWrap pandoc 的换行模式
+	KeyConverterWrap = "converter.wrap"
+	// KeyConverterExtractMedia 文档中图片等媒体文件的导出目录
+	KeyConverterExtractMedia = "converter.extract_media"
+	// KeyConverterOutputDir 生成的 markdown 与媒体文件的目录，为空时写在文档旁边
+	KeyConverterOutputDir = "converter.output_dir"
+	// KeyConverterSlideNotes 演示文稿是否输出演讲者备注
+	KeyConverterSlideNotes = "converter.slide_notes"
+	// KeyConverterSheetFormat 电子表格的输出格式
+	KeyConverterSheetFormat = "converter.sheet_format"
+	// KeyConverterFilters pandoc 使用的 lua filter
+	KeyConverterFilters = "converter.filters"
+	// KeyConverterTrackChanges pandoc 处理修订的方式
+	KeyConverterTrackChanges = "converter.track_changes"
+	// KeyConverterComments Word 批注的导出格式
+	KeyConverterComments = "converter.comments"
+	// KeyConverterRules 按路径匹配的转换参数
+	KeyConverterRules = "converter.rules"
+	// KeyFrontMatterEnabled 是否在生成的 markdown 开头写入源文档的元数据
+	KeyFrontMatterEnabled = "front_matter.enabled"
+	// KeyIdentityEnabled 是否在 Office 文档中记录稳定的文档 ID
+	KeyIdentityEnabled = "identity.enabled"
+	// KeyScrubEnabled 转换前是否清理 docx 中的个人信息
+	KeyScrubEnabled = "scrub.enabled"
+	// KeyScrubPseudonym 清理 docx 时替换作者等人名的文字
+	KeyScrubPseudonym = "scrub.pseudonym"
+	// KeyNormalizePasses 转换后对 markdown 执行的规范化步骤
+	KeyNormalizePasses = "normalize.passes"
+	// KeyNormalizeLineWidth 规范化时段落的换行宽度
+	KeyNormalizeLineWidth = "normalize.line_width"
+	// KeyNormalizeSentencePerLine 规范化时是否每个句子单独一行
+	KeyNormalizeSentencePerLine = "normalize.sentence_per_line"
+	// KeyPolicyMaxSize 提交的文件大小上限
+	KeyPolicyMaxSize = "policy.max_size"
+	// KeyPolicyRequiredFrontMatter 生成的 markdown 必须包含的元数据字段
+	KeyPolicyRequiredFrontMatter = "policy.required_front_matter"
+	// KeyPolicyForbidTrackedChanges 是否禁止提交带有未处理修订的文档
+	KeyPolicyForbidTrackedChanges = "policy.forbid_tracked_changes"
+	// KeyPolicyForbidComments 是否禁止提交带有批注的文档
+	KeyPolicyForbidComments = "policy.forbid_comments"
+	// KeyPolicyFilenamePattern 文档文件名需要匹配的正则
+	KeyPolicyFilenamePattern = "policy.filename_pattern"
+	// KeyPolicyFrozen 禁止修改的路径
+	KeyPolicyFrozen = "policy.frozen"
+	// KeySensitiveRules 提交前检查敏感信息时启用的内置规则
+	KeySensitiveRules = "sensitive.rules"
+	// KeySensitivePatterns 提交前检查敏感信息时使用的自定义正则
+	KeySensitivePatterns = "sensitive.patterns"
+	// KeySensitiveAllowlist 检查敏感信息时忽略的文本
+	KeySensitiveAllowlist = "sensitive.allowlist"
+	// KeyLockBackend 文档锁的存储方式
+	KeyLockBackend = "lock.backend"
+	// KeyLockEnforce 提交或推送被其他人锁定的文档时的处理方式
+	KeyLockEnforce = "lock.enforce"
+	// KeyScanIgnore 扫描文档时忽略的文件
+	KeyScanIgnore = "scan.ignore"
+	// KeyScanRoots 扫描的文档根目录
+	KeyScanRoots = "scan.roots"
+	// KeyScanUseGitignore 扫描文档时是否遵循 .gitignore
+	KeyScanUseGitignore = "scan.use_gitignore"
+	// KeyCommitTemplate commit 信息模板
+	KeyCommitTemplate = "commit.template"
+	// KeyHooksPreConvert 转换文档前执行的命令
+	KeyHooksPreConvert = "hooks.pre_convert"
+	// KeyHooksPostConvert 转换文档后执行的命令
+	KeyHooksPostConvert = "hooks.post_convert"
+	// KeyHooksPreCommit 提交前执行的命令
+	KeyHooksPreCommit = "hooks.pre_commit"
+	// KeyHooksPostCommit 提交后执行的命令
+	KeyHooksPostCommit = "hooks.post_commit"
+	// KeyHooksPrePush 推送前执行的命令
+	KeyHooksPrePush = "hooks.pre_push"
+	// KeyHooksPostPush 推送后执行的命令
+	KeyHooksPostPush = "hooks.post_push"
+	// KeyWatchDebounce 文档保存后等待多久再转换，与 watch 命令的 --debounce 参数相同
+	KeyWatchDebounce = "watch.debounce"
+	// KeyWatchAutoCommit watch 是否自动提交，与 watch 命令的 --auto-commit 参数相同
+	KeyWatchAutoCommit = "watch.auto_commit"
+	// KeyWatchCommitInterval watch 自动提交的间隔，与 watch 命令的 --commit-interval 参数相同
+	KeyWatchCommitInterval = "watch.commit_interval"
+)
+
+var schema = []Key{
+	{
+		Name:    KeyConverterFormat,
+		Kind:    KindString,
+		Default: "markdown",
+		Enum:    []string{"markdown", "gfm", "commonmark"},
+		Usage:   "生成的 markdown 格式",
+	},
+	{
+		Name:    KeyConverterWrap,
+		Kind:    KindString,
+		Default: "auto",
+		Enum:    []string{"auto", "none", "preserve"},
+		Usage:   "pandoc 换行模式",
+	},
+	{
+		Name:    KeyConverterExtractMedia,
+		Kind:    KindString,
+		Default: ".",
+		Usage:   "文档中图片等媒体文件的导出目录",
+	},
+	{
+		Name:     KeyConverterOutputDir,
+		Kind:     KindString,
+		Default:  "",
+		Usage:    "生成的 markdown 与媒体文件的目录，按文档的相对路径组织，如 .gitdoc/md；为空时写在文档旁边",
+		Validate: validateOutputDir,
+	},
+	{
+		Name:    KeyConverterSlideNotes,
+		Kind:    KindBool,
+		Default: true,
+		Usage:   "演示文稿是否输出演讲者备注",
+	},
+	{
+		Name:    KeyConverterSheetFormat,
+		Kind:    KindString,
+		Default: "markdown",
+		Enum:    []string{"markdown", "csv"},
+		Usage:   "电子表格的输出格式，markdown 为每个工作表一个表格，csv 为每个工作表一个 csv 文件",
+	},
+	{
+		Name:    KeyConverterFilters,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "pandoc 使用的 lua filter 路径，相对仓库根目录",
+	},
+	{
+		Name:    KeyConverterTrackChanges,
+		Kind:    KindString,
+		Default: "accept",
+		Enum:    []string{"accept", "reject", "all"},
+		Usage:   "pandoc 处理 docx 修订的方式，accept 为接受修订，reject 为拒绝修订，all 为保留全部修订并显示为 {++插入++} {--删除--}",
+	},
+	{
+		Name:    KeyConverterComments,
+		Kind:    KindString,
+		Default: "none",
+		Enum:    []string{"none", "markdown", "json"},
+		Usage:   "Word 批注的导出格式，markdown 导出为 <文档名>.comments.md，json 导出为 <文档名>.comments.json，none 不导出",
+	},
+	{
+		Name:    KeyConverterRules,
+		Kind:    KindList,
+		Default: []interface{}{},
+		Usage:   "按路径匹配的转换参数，如 [{match: \"contracts/**\", format: gfm, track_changes: all}]，后面的规则覆盖前面的",
+	},
+	{
+		Name:    KeyFrontMatterEnabled,
+		Kind:    KindBool,
+		Default: true,
+		Usage:   "是否在生成的 markdown 开头写入 yaml 元数据，包括源文档路径、内容 sha256、转换器版本与文档核心属性",
+	},
+	{
+		Name:    KeyIdentityEnabled,
+		Kind:    KindBool,
+		Default: false,
+		Usage: "是否在 docx/pptx/xlsx 的自定义属性 gitdoc-id 中记录稳定的文档 ID 并写入 markdown 元数据，会修改源文档；" +
+			"未开启时按内容 sha256 与文字相似度识别重命名的文档",
+	},
+	{
+		Name:    KeyScrubEnabled,
+		Kind:    KindBool,
+		Default: false,
+		Usage:   "转换前是否原地改写 docx，清理作者、最后修改者、公司、rsid 与自定义 XML，并固定 zip 中的修改时间",
+	},
+	{
+		Name:    KeyScrubPseudonym,
+		Kind:    KindString,
+		Default: "",
+		Usage:   "清理 docx 时替换作者、最后修改者及修订与批注作者的文字，为空时直接清空",
+	},
+	{
+		Name:     KeyNormalizePasses,
+		Kind:     KindStrings,
+		Default:  markdown.PassNames(),
+		Usage:    "转换后对 markdown 执行的规范化步骤，可选: " + strings.Join(markdown.PassNames(), ", ") + "，为空时不做规范化",
+		Validate: validatePasses,
+	},
+	{
+		Name:    KeyNormalizeLineWidth,
+		Kind:    KindInt,
+		Default: 0,
+		Usage:   "规范化时段落的换行宽度，0 表示每个段落一行",
+		Validate: func(value interface{}) error {
+			if value.(int) < 0 {
+				return fmt.Errorf("换行宽度不能小于 0")
+			}
+			return nil
+		},
+	},
+	{
+		Name:    KeyNormalizeSentencePerLine,
+		Kind:    KindBool,
+		Default: false,
+		Usage:   "规范化时段落中每个句子单独一行，按中文（。！？；）与西文句末标点断句，需要启用 wrap 步骤",
+	},
+	{
+		Name:    KeyPolicyMaxSize,
+		Kind:    KindString,
+		Default: "",
+		Usage:   "提交的文件大小上限，如 20MB，为空时不限制",
+		Validate: func(value interface{}) error {
+			if value.(string) == "" {
+				return nil
+			}
+			_, err := utils.ParseSize(value.(string))
+			return err
+		},
+	},
+	{
+		Name:    KeyPolicyRequiredFrontMatter,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "生成的 markdown 元数据中必须存在且不为空的字段，如 title、author",
+	},
+	{
+		Name:    KeyPolicyForbidTrackedChanges,
+		Kind:    KindBool,
+		Default: false,
+		Usage:   "是否禁止提交带有未接受或拒绝的修订的 docx",
+	},
+	{
+		Name:    KeyPolicyForbidComments,
+		Kind:    KindBool,
+		Default: false,
+		Usage:   "是否禁止提交带有批注的 docx",
+	},
+	{
+		Name:    KeyPolicyFilenamePattern,
+		Kind:    KindString,
+		Default: "",
+		Usage:   "文档文件名（不含目录）需要匹配的正则，如 ^[a-z0-9-]+\\.docx$，为空时不检查",
+		Validate: func(value interface{}) error {
+			_, err := regexp.Compile(value.(string))
+			return err
+		},
+	},
+	{
+		Name:    KeyPolicyFrozen,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "禁止修改或删除的路径，语法与 .gitignore 相同，如 archive/**",
+	},
+	{
+		Name:     KeySensitiveRules,
+		Kind:     KindStrings,
+		Default:  []string{sensitive.RuleIDCard, sensitive.RuleBankCard, sensitive.RuleSecret},
+		Usage:    "提交前检查转换后的文本时启用的内置规则，可选: " + strings.Join(sensitive.BuiltinNames(), ", ") + "；email 与 phone 在常见文档中容易误报，默认不启用",
+		Validate: validateSensitiveRules,
+	},
+	{
+		Name:     KeySensitivePatterns,
+		Kind:     KindList,
+		Default:  []interface{}{},
+		Usage:    "提交前检查转换后的文本时使用的自定义规则，如 [{name: contract_no, pattern: \"HT-\\\\d{8}\"}]",
+		Validate: validateSensitivePatterns,
+	},
+	{
+		Name:     KeySensitiveAllowlist,
+		Kind:     KindStrings,
+		Default:  []string{},
+		Usage:    "检查敏感信息时忽略的文本，每项为正则，命中的文本匹配任一正则时不报告，如 @example\\.com$",
+		Validate: validateRegexps,
+	},
+	{
+		Name:    KeyLockBackend,
+		Kind:    KindString,
+		Default: "file",
+		Enum:    []string{"file", "lfs"},
+		Usage:   "文档锁的存储方式，file 记录在提交到仓库的 .gitdoc/locks.yml 中，lfs 使用 Git LFS 的文件锁",
+	},
+	{
+		Name:    KeyLockEnforce,
+		Kind:    KindString,
+		Default: "refuse",
+		Enum:    []string{"warn", "refuse"},
+		Usage:   "提交或推送被其他人锁定的文档时的处理方式，warn 只输出警告，refuse 终止提交或推送",
+	},
+	{
+		Name:    KeyScanIgnore,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "扫描文档时忽略的文件，语法与 .gitignore 相同，会与 .gitdocignore 中的规则合并",
+	},
+	{
+		Name:    KeyScanRoots,
+		Kind:    KindStrings,
+		Default: []string{"."},
+		Usage:   "扫描的文档根目录，相对仓库根目录",
+	},
+	{
+		Name:    KeyScanUseGitignore,
+		Kind:    KindBool,
+		Default: false,
+		Usage:   "扫描文档时是否同时遵循 .gitignore",
+	},
+	{
+		Name:    KeyCommitTemplate,
+		Kind:    KindString,
+		Default: "",
+		Usage:   "commit 信息模板，使用 go template 语法，如 \"docs: {{.Message}}\"",
+	},
+	{
+		Name:    KeyHooksPreConvert,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "转换文档前执行的命令，失败时终止转换",
+	},
+	{
+		Name:    KeyHooksPostConvert,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "转换文档后执行的命令，失败时终止后续步骤",
+	},
+	{
+		Name:    KeyHooksPreCommit,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "提交前执行的命令，失败时终止提交",
+	},
+	{
+		Name:    KeyHooksPostCommit,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "提交后执行的命令",
+	},
+	{
+		Name:    KeyHooksPrePush,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "推送前执行的命令，失败时终止推送",
+	},
+	{
+		Name:    KeyHooksPostPush,
+		Kind:    KindStrings,
+		Default: []string{},
+		Usage:   "推送后执行的命令",
+	},
+	{
+		Name:    KeyWatchDebounce,
+		Kind:    KindDuration,
+		Default: "3s",
+		Usage:   "watch 时文档保存后等待多久再转换",
+	},
+	{
+		Name:    KeyWatchAutoCommit,
+		Kind:    KindBool,
+		Default: false,
+		Usage:   "watch 时是否定时自动提交转换结果",
+	},
+	{
+		Name:    KeyWatchCommitInterval,
+		Kind:    KindDuration,
+		Default: "10m",
+		Usage:   "watch 自动提交的间隔",
+	},
+}
+
+// validatePasses 校验规范化步骤名称
+func validatePasses(value interface{}) error {
+	for _, item := range value.([]interface{}) {
+		if name, _ := item.(string); !utils.IsContains(markdown.PassNames(), name) {
+			return fmt.Errorf("未知的规范化步骤 %v，可选: %s", item, strings.Join(markdown.PassNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// validateSensitiveRules 校验 sensitive.rules 中的规则名称
+func validateSensitiveRules(value interface{}) error {
+	for _, item := range value.([]interface{}) {
+		if name, _ := item.(string); !utils.IsContains(sensitive.BuiltinNames(), name) {
+			return fmt.Errorf("未知的内置规则 %v，可选: %s", item, strings.Join(sensitive.BuiltinNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// validateSensitivePatterns 校验 sensitive.patterns 的每条规则
+func validateSensitivePatterns(value interface{}) error {
+	for i, item := range value.([]interface{}) {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("第 %d 条规则应为对象，实际为 %v", i+1, item)
+		}
+		name, _ := rule["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("第 %d 条规则缺少 name", i+1)
+		}
+		pattern, _ := rule["pattern"].(string)
+		if pattern == "" {
+			return fmt.Errorf("第 %d 条规则缺少 pattern", i+1)
+		}
+		if _, err := sensitive.NewRule(name, pattern); err != nil {
+			return fmt.Errorf("第 %d 条%v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateRegexps 校验列表中的每一项都是合法的正则
+func validateRegexps(value interface{}) error {
+	for _, item := range value.([]interface{}) {
+		if _, err := regexp.Compile(fmt.Sprint(item)); err != nil {
+			return fmt.Errorf("%v 不是合法的正则: %v", item, err)
+		}
+	}
+	return nil
+}
+
+// validateOutputDir 校验输出目录是仓库内的相对路径
+func validateOutputDir(value interface{}) error {
+	raw := fmt.Sprint(value)
+	if raw == "" {
+		return nil
+	}
+	dir := filepath.Clean(raw)
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%v 应为仓库内的相对路径", value)
+	}
+	return nil
+}
+
+// Keys 返回按名称排序的全部配置项
+func Keys() []Key {
+	keys := make([]Key, len(schema))
+	copy(keys, schema)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// Lookup 根据名称查找配置项
+func Lookup(name string) (Key, bool) {
+	name = strings.ToLower(name)
+	for _, key := range schema {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// isSection name 是否为某些配置项的上级，如 converter
+func isSection(name string, extra []string) bool {
+	prefix := strings.ToLower(name) + "."
+	for _, key := range schema {
+		if strings.HasPrefix(key.Name, prefix) {
+			return true
+		}
+	}
+	for _, key := range extra {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates 返回全部配置项及其上级的名称
+func candidates() []string {
+	var names []string
+	for _, key := range schema {
+		parts := strings.Split(key.Name, ".")
+		for i := range parts {
+			if name := strings.Join(parts[:i+1], "."); !utils.IsContains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// SetDefaults 将配置项的默认值注册到 viper
+func SetDefaults() {
+	for _, key := range schema {
+		if key.Default != nil {
+			viper.SetDefault(key.Name, key.Default)
+		}
+	}
+}
+
+// Suggest 返回与 name 最相近的配置项名称，没有相近的返回空字符串
+func Suggest(name string) string {
+	name = strings.ToLower(name)
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range candidates() {
+		if d := distance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// distance 计算两个字符串的编辑距离
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// Validate 校验配置文件内容，返回全部问题；extra 为 schema 之外的合法配置项，如命令行参数
+func Validate(values map[string]interface{}, extra []string) []error {
+	var errs []error
+	validateMap("", values, extra, &errs)
+	return errs
+}
+
+func validateMap(prefix string, values map[string]interface{}, extra []string, errs *[]error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		full := strings.ToLower(prefix + name)
+		value := values[name]
+		if key, ok := Lookup(full); ok {
+			if err := CheckValue(key, value); err != nil {
+				*errs = append(*errs, err)
+			}
+			continue
+		}
+		if utils.IsContains(extra, full) {
+			continue
+		}
+		if sub, ok := value.(map[string]interface{}); ok && isSection(full, extra) {
+			validateMap(full+".", sub, extra, errs)
+			continue
+		}
+		if suggestion := Suggest(full); suggestion != "" {
+			*errs = append(*errs, fmt.Errorf("未知的配置项 %s，是否想设置 %s", full, suggestion))
+		} else {
+			*errs = append(*errs, fmt.Errorf("未知的配置项 %s", full))
+		}
+	}
+}
+
+// CheckValue 校验 value 是否符合配置项的类型与约束
+func CheckValue(key Key, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	switch key.Kind {
+	case KindString:
+		s, ok := value.(string)
+		if !ok {
+			return typeError(key, value)
+		}
+		if len(key.Enum) > 0 && !utils.IsContains(key.Enum, s) {
+			return fmt.Errorf("配置项 %s 的值 %q 不合法，可选值: %s", key.Name, s, strings.Join(key.Enum, ", "))
+		}
+	case KindBool:
+		if _, ok := value.(bool); !ok {
+			return typeError(key, value)
+		}
+	case KindInt:
+		if _, ok := value.(int); !ok {
+			return typeError(key, value)
+		}
+	case KindStrings:
+		list, ok := value.([]interface{})
+		if !ok {
+			return typeError(key, value)
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("配置项 %s 的元素 %v 应为字符串", key.Name, item)
+			}
+		}
+	case KindDuration:
+		s, ok := value.(string)
+		if !ok {
+			return typeError(key, value)
+		}
+		if _, err := time.ParseDuration(s); err != nil {
+			return fmt.Errorf("配置项 %s 的值 %q 不是合法的时长: %v", key.Name, s, err)
+		}
+	case KindList:
+		if _, ok := value.([]interface{}); !ok {
+			return typeError(key, value)
+		}
+	default:
+	}
+	if key.Validate != nil {
+		if err := key.Validate(value); err != nil {
+			return fmt.Errorf("配置项 %s 不合法: %v", key.Name, err)
+		}
+	}
+	return nil
+}
+
+func typeError(key Key, value interface{}) error {
+	return fmt.Errorf("配置项 %s 应为 %s 类型，实际为 %v", key.Name, key.Kind, value)
+}
+
+// ParseValue 将命令行输入的字符串按配置项类型解析为对应的值
+func ParseValue(key Key, raw string) (interface{}, error) {
+	var value interface{}
+	switch key.Kind {
+	case KindBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("配置项 %s 应为 bool 类型: %v", key.Name, err)
+		}
+		value = b
+	case KindInt:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("配置项 %s 应为 int 类型: %v", key.Name, err)
+		}
+		value = i
+	case KindStrings:
+		list := make([]interface{}, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		value = list
+	case KindList:
+		var list []interface{}
+		if err := unmarshalYaml(raw, &list); err != nil {
+			return nil, fmt.Errorf("配置项 %s 应为 yaml 列表: %v", key.Name, err)
+		}
+		value = list
+	default:
+		value = raw
+	}
+	if err := CheckValue(key, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -11,6 +11,15 @@ const (
 )
 
 const (
-	// 日志格式
-	LogFormat = `%{color}%{time:15:04:05} %{shortfunc} [%{level:.4s}]%{color:reset} %{message}`
+	// ConfigFile 配置文件路径
+	ConfigFile = ".gitdoc-cli.yml"
+	// IgnoreFile 扫描文档时的忽略文件，语法与 .gitignore 相同
+	IgnoreFile = ".gitdocignore"
+	// LocksFile 记录文档锁的文件，需要提交到仓库中
+	LocksFile = ".gitdoc/locks.yml"
+)
+
+const (
+	// EnvInternal 由 gitdoc-cli 发起的 git 命令会带上该环境变量，git hooks 据此跳过重复的转换与检查
+	EnvInternal = "GITDOC_CLI_INTERNAL"
 )
@@ -0,0 +1,91 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+const (
+	// CommentsNone 不导出批注
+	CommentsNone = "none"
+	// CommentsMarkdown 批注导出为 <文档名>.comments.md
+	CommentsMarkdown = "markdown"
+	// CommentsJSON 批注导出为 <文档名>.comments.json
+	CommentsJSON = "json"
+)
+
+// commentsPath 返回批注文件的路径
+func commentsPath(dst, format string) string {
+	base := strings.TrimSuffix(dst, filepath.Ext(dst))
+	if format == CommentsJSON {
+		return base + ".comments.json"
+	}
+	return base + ".comments.md"
+}
+
+// writeComments 将 docx 的批注导出到 dst 旁边的批注文件，返回生成的文件；文档没有批注时删除之前生成的批注文件
+func writeComments(src, dst, format string) ([]string, error) {
+	comments, err := office.ReadComments(src)
+	if err != nil {
+		return nil, err
+	}
+	p := commentsPath(dst, format)
+	if len(comments) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("删除 %s 失败: %v", p, err)
+		}
+		return nil, nil
+	}
+
+	var content []byte
+	if format == CommentsJSON {
+		content, err = json.MarshalIndent(comments, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("生成 %s 失败: %v", p, err)
+		}
+		content = append(content, '\n')
+	} else {
+		content = []byte(renderComments(filepath.Base(src), comments))
+	}
+	if err := os.WriteFile(p, content, 0644); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %v", p, err)
+	}
+	return []string{p}, nil
+}
+
+// renderComments 生成批注的 markdown，每条批注一个章节，引用批注对应的正文
+func renderComments(name string, comments []office.Comment) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# 批注: %s\n", escapeInline(name))
+	for i, c := range comments {
+		heading := fmt.Sprintf("%d. %s", i+1, escapeInline(c.Author))
+		if date := formatDate(c.Date); date != "" {
+			heading += " " + date
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", heading)
+		if c.Anchor != "" {
+			fmt.Fprintf(&sb, "> %s\n\n", escapeInline(c.Anchor))
+		}
+		lines := strings.Split(c.Text, "\n")
+		for j := range lines {
+			lines[j] = escapeInline(lines[j])
+		}
+		sb.WriteString(strings.Join(lines, "\n\n") + "\n")
+	}
+	return sb.String()
+}
+
+// formatDate 将批注时间格式化为 2006-01-02 15:04，无法解析时原样返回
+func formatDate(date string) string {
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return date
+	}
+	return t.Format("2006-01-02 15:04")
+}
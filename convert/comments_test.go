@@ -0,0 +1,17 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+func TestRenderComments(t *testing.T) {
+	content := renderComments("合同.docx", []office.Comment{
+		{ID: "0", Author: "张三", Date: "2024-01-02T03:04:05Z", Anchor: "三日内", Text: "改为\n五日"},
+		{ID: "1", Author: "李四", Text: "同意"},
+	})
+	assert.EqualValues(t, "# 批注: 合同.docx\n\n## 1. 张三 2024-01-02 03:04\n\n> 三日内\n\n改为\n\n五日\n\n## 2. 李四\n\n同意\n", content)
+	assert.EqualValues(t, "a/b.comments.json", commentsPath("a/b.md", CommentsJSON))
+}
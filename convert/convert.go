@@ -0,0 +1,208 @@
+// Package convert 将各类文档转换为便于 diff 的 markdown 等文本格式
+package convert
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+// Converter 文档转换器
+type Converter interface {
+	// Name 转换器名称
+	Name() string
+	// Version 转换器版本，写入生成的 markdown 的元数据
+	Version() string
+	// Convert 转换文档 src，dst 为生成的 markdown 路径，返回实际生成的文件，其中总是包含 dst
+	Convert(src, dst string) ([]string, error)
+}
+
+// Planner 转换时可能生成 markdown 以外文件的转换器实现，用于在不转换的情况下预测生成的文件
+type Planner interface {
+	// Plan 返回转换文档 src 将要生成的文件，dst 为生成的 markdown 路径，结果与 Convert 一致且总是包含 dst
+	Plan(src, dst string) ([]string, error)
+}
+
+// registry 扩展名与转换器的映射
+var registry = map[string]Converter{}
+
+func init() {
+	Register(pandoc{}, ".doc", ".docx", ".odt", ".rtf")
+	Register(slides{}, ".pptx")
+	Register(sheets{}, ".xlsx")
+}
+
+// Register 注册转换器，extensions 需要带 . 前缀，后注册的会覆盖先注册的
+func Register(c Converter, extensions ...string) {
+	for _, ext := range extensions {
+		registry[strings.ToLower(ext)] = c
+	}
+}
+
+// Extensions 返回全部支持转换的扩展名
+func Extensions() []string {
+	extensions := make([]string, 0, len(registry))
+	for ext := range registry {
+		extensions = append(extensions, ext)
+	}
+	sort.Strings(extensions)
+	return extensions
+}
+
+// Lookup 返回文档对应的转换器
+func Lookup(src string) (Converter, bool) {
+	c, ok := registry[strings.ToLower(filepath.Ext(src))]
+	return c, ok
+}
+
+// outputDir 返回配置的生成文件目录，为空时生成的文件写在文档旁边
+func outputDir() string {
+	dir := viper.GetString(config.KeyConverterOutputDir)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Clean(dir)
+}
+
+// OutputPath 返回文档生成的 markdown 路径，配置了 converter.output_dir 时位于该目录下与文档相同的相对路径
+func OutputPath(src string) string {
+	output := strings.TrimSuffix(src, filepath.Ext(src)) + ".md"
+	if dir := outputDir(); dir != "" {
+		return filepath.Join(dir, output)
+	}
+	return output
+}
+
+// UpToDate 文档生成的 markdown 是否已是最新：元数据中记录的源文档路径、sha256、转换器版本与转换配置都与当前一致
+func UpToDate(src string) bool {
+	c, ok := Lookup(src)
+	if !ok {
+		return false
+	}
+	fm, ok, err := ReadFrontMatter(OutputPath(src))
+	if err != nil || !ok {
+		return false
+	}
+	sum, err := fileSHA256(src)
+	if err != nil {
+		return false
+	}
+	// 重命名后沿用的 markdown 记录的仍是原来的文档，需要重新转换，否则会被当作已删除文档生成的文件删除
+	return fm.Source == filepath.ToSlash(filepath.Clean(src)) && fm.SourceSHA256 == sum &&
+		fm.Converter == c.Name() && fm.ConverterVersion == c.Version() && fm.OptionsSHA256 == OptionsSHA256(src)
+}
+
+// Files 依次转换 docs，force 为 false 时跳过生成的 markdown 已是最新的文档；单个文档转换失败不会中断，结果记录在返回值中
+func Files(docs []string, force bool) []Result {
+	results := make([]Result, 0, len(docs))
+	if len(docs) == 0 {
+		return results
+	}
+	bar := log.NewProgress("转换文档", len(docs))
+	defer bar.Finish()
+	for _, doc := range docs {
+		start := time.Now()
+		task := bar.Start(doc)
+		res := Result{Source: doc, Status: StatusConverted}
+		if skip(doc, force) {
+			task.Debug("未变更，跳过转换")
+			res.Status = StatusSkipped
+			res.Outputs = outputFiles(OutputPath(doc))
+		} else {
+			task.Debug("正在转换为 %s", OutputPath(doc))
+			outputs, err := File(doc)
+			if err != nil {
+				task.Error("转换失败: %v", err)
+				res.Status = StatusFailed
+				res.Error = err.Error()
+			}
+			res.Outputs = outputs
+		}
+		res.Size = totalSize(res.Outputs)
+		res.Duration = time.Since(start)
+		results = append(results, res)
+		bar.Done(doc)
+	}
+	return results
+}
+
+// Plan 返回 Files 转换 docs 的结果而不实际转换，跳过规则与 Files 相同，Outputs 为将要生成或沿用的文件
+func Plan(docs []string, force bool) []Result {
+	results := make([]Result, 0, len(docs))
+	for _, doc := range docs {
+		res := Result{Source: doc, Status: StatusConverted}
+		if skip(doc, force) {
+			res.Status = StatusSkipped
+			res.Outputs = outputFiles(OutputPath(doc))
+		} else if outputs, err := planFile(doc); err != nil {
+			res.Status = StatusFailed
+			res.Error = err.Error()
+		} else {
+			res.Outputs = outputs
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// skip 是否跳过转换文档，force 为 false 时跳过生成的 markdown 已是最新的文档
+func skip(doc string, force bool) bool {
+	return !force && UpToDate(doc)
+}
+
+// planFile 返回转换单个文档将要生成的文件
+func planFile(src string) ([]string, error) {
+	c, ok := Lookup(src)
+	if !ok {
+		return nil, fmt.Errorf("不支持转换 %s 类型的文档", filepath.Ext(src))
+	}
+	dst := OutputPath(src)
+	if p, ok := c.(Planner); ok {
+		return p.Plan(src, dst)
+	}
+	return []string{dst}, nil
+}
+
+// File 转换单个文档，返回生成的文件
+func File(src string) ([]string, error) {
+	c, ok := Lookup(src)
+	if !ok {
+		return nil, fmt.Errorf("不支持转换 %s 类型的文档", filepath.Ext(src))
+	}
+	if viper.GetBool(config.KeyScrubEnabled) && strings.EqualFold(filepath.Ext(src), ".docx") {
+		scrubbed, err := office.Scrub(src, viper.GetString(config.KeyScrubPseudonym))
+		if err != nil {
+			return nil, fmt.Errorf("清理 %s 的个人信息失败: %v", src, err)
+		}
+		if scrubbed {
+			log.Debug("已清理 %s 的个人信息", src)
+		}
+	}
+	if viper.GetBool(config.KeyIdentityEnabled) {
+		if _, err := ensureID(src); err != nil {
+			return nil, fmt.Errorf("写入 %s 的文档 ID 失败: %v", src, err)
+		}
+	}
+	dst := OutputPath(src)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %v", err)
+	}
+	outputs, err := c.Convert(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if viper.GetBool(config.KeyFrontMatterEnabled) {
+		if err := writeFrontMatter(src, dst, c); err != nil {
+			return nil, err
+		}
+	}
+	return outputs, nil
+}
@@ -0,0 +1,47 @@
+package convert
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/config"
+)
+
+type fakePlanner struct {
+	fakeConverter
+}
+
+func (fakePlanner) Plan(src, dst string) ([]string, error) {
+	return []string{dst, "b.comments.md"}, nil
+}
+
+func TestPlan(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	Register(fakeConverter{}, ".fake")
+	defer delete(registry, ".fake")
+	Register(fakePlanner{}, ".plan")
+	defer delete(registry, ".plan")
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.Nil(t, os.WriteFile("a.fake", []byte("abc"), 0644))
+	assert.Nil(t, os.WriteFile("a.md", []byte("# 正文\n"), 0644))
+	assert.Nil(t, os.WriteFile("a.comments.md", []byte(""), 0644))
+	assert.Nil(t, writeFrontMatter("a.fake", "a.md", fakeConverter{}))
+	assert.Nil(t, os.WriteFile("b.plan", []byte("abc"), 0644))
+
+	docs := []string{"a.fake", "b.plan", "c.unknown"}
+	assert.Equal(t, []Result{
+		{Source: "a.fake", Status: StatusSkipped, Outputs: []string{"a.md", "a.comments.md"}},
+		{Source: "b.plan", Status: StatusConverted, Outputs: []string{"b.md", "b.comments.md"}},
+		{Source: "c.unknown", Status: StatusFailed, Error: "不支持转换 .unknown 类型的文档"},
+	}, Plan(docs, false))
+
+	// force 时与 Files 一样重新转换未变更的文档
+	assert.Equal(t, Result{Source: "a.fake", Status: StatusConverted, Outputs: []string{"a.md"}}, Plan(docs, true)[0])
+}
@@ -0,0 +1,164 @@
+package convert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/office"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter 生成的 markdown 开头的 yaml 元数据，记录源文档的信息
+type FrontMatter struct {
+	// Source 源文档路径
+	Source string `yaml:"source"`
+	// DocID 文档 ID，记录在 Office 文档的自定义属性中，重命名后保持不变
+	DocID string `yaml:"doc_id,omitempty"`
+	// SourceSHA256 源文档内容的 sha256
+	SourceSHA256 string `yaml:"source_sha256"`
+	// Converter 转换器名称
+	Converter string `yaml:"converter"`
+	// ConverterVersion 转换器版本
+	ConverterVersion string `yaml:"converter_version"`
+	// OptionsSHA256 转换时配置的 sha256，见 OptionsSHA256
+	OptionsSHA256 string `yaml:"options_sha256,omitempty"`
+	// Title 等字段来自 Office 文档的核心属性
+	Title          string `yaml:"title,omitempty"`
+	Author         string `yaml:"author,omitempty"`
+	LastModifiedBy string `yaml:"last_modified_by,omitempty"`
+	Revision       string `yaml:"revision,omitempty"`
+}
+
+// ooxml 可以读取核心属性的 Office Open XML 文档
+var ooxml = map[string]bool{".docx": true, ".pptx": true, ".xlsx": true}
+
+// splitFrontMatter 拆分 markdown 开头的 yaml 元数据与正文，没有元数据时 header 为空
+func splitFrontMatter(content string) (header, body string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+	lines := strings.SplitAfter(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], "\n"); line == "---" || line == "..." {
+			return strings.Join(lines[1:i], ""), strings.Join(lines[i+1:], "")
+		}
+	}
+	return "", content
+}
+
+// ReadFrontMatter 读取 markdown 文件开头的元数据，文件没有元数据时 ok 为 false
+func ReadFrontMatter(p string) (fm FrontMatter, ok bool, err error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return fm, false, fmt.Errorf("读取 %s 失败: %v", p, err)
+	}
+	header, _ := splitFrontMatter(string(content))
+	if header == "" {
+		return fm, false, nil
+	}
+	if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+		return fm, false, fmt.Errorf("解析 %s 的元数据失败: %v", p, err)
+	}
+	return fm, fm.Source != "", nil
+}
+
+// FrontMatterFields 返回 markdown 开头元数据中的全部字段，没有元数据时返回空
+func FrontMatterFields(content []byte) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	header, _ := splitFrontMatter(string(content))
+	if header == "" {
+		return fields, nil
+	}
+	if err := yaml.Unmarshal([]byte(header), &fields); err != nil {
+		return nil, fmt.Errorf("解析元数据失败: %v", err)
+	}
+	return fields, nil
+}
+
+// newFrontMatter 生成源文档 src 的元数据
+func newFrontMatter(src string, c Converter) (FrontMatter, error) {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return FrontMatter{}, fmt.Errorf("读取 %s 失败: %v", src, err)
+	}
+	sum := sha256.Sum256(content)
+	fm := FrontMatter{
+		Source:           filepath.ToSlash(filepath.Clean(src)),
+		SourceSHA256:     hex.EncodeToString(sum[:]),
+		Converter:        c.Name(),
+		ConverterVersion: c.Version(),
+		OptionsSHA256:    OptionsSHA256(src),
+	}
+	if ooxml[strings.ToLower(filepath.Ext(src))] {
+		props, err := office.ReadCoreProperties(src)
+		if err != nil {
+			log.Debug("读取 %s 的核心属性失败: %v", src, err)
+		}
+		fm.DocID = readID(src)
+		fm.Title = props.Title
+		fm.Author = props.Creator
+		fm.LastModifiedBy = props.LastModifiedBy
+		fm.Revision = props.Revision
+	}
+	return fm, nil
+}
+
+// writeFrontMatter 在 dst 开头写入源文档 src 的元数据；转换器已生成的元数据中与之不同名的字段会保留在后面
+func writeFrontMatter(src, dst string, c Converter) error {
+	fm, err := newFrontMatter(src, c)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(dst)
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %v", dst, err)
+	}
+	header, body := splitFrontMatter(string(content))
+
+	var node yaml.Node
+	if err := node.Encode(fm); err != nil {
+		return fmt.Errorf("生成 %s 的元数据失败: %v", dst, err)
+	}
+	if header != "" {
+		var existing yaml.Node
+		if err := yaml.Unmarshal([]byte(header), &existing); err != nil || len(existing.Content) == 0 ||
+			existing.Content[0].Kind != yaml.MappingNode {
+			log.Debug("忽略 %s 中无法解析的元数据", dst)
+		} else {
+			mergeMapping(&node, existing.Content[0])
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("生成 %s 的元数据失败: %v", dst, err)
+	}
+	buf.WriteString("---\n\n")
+	buf.WriteString(strings.TrimLeft(body, "\n"))
+	if err := os.WriteFile(dst, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", dst, err)
+	}
+	return nil
+}
+
+// mergeMapping 将 from 中 to 没有的字段追加到 to
+func mergeMapping(to, from *yaml.Node) {
+	keys := make(map[string]bool)
+	for i := 0; i+1 < len(to.Content); i += 2 {
+		keys[to.Content[i].Value] = true
+	}
+	for i := 0; i+1 < len(from.Content); i += 2 {
+		if !keys[from.Content[i].Value] {
+			to.Content = append(to.Content, from.Content[i], from.Content[i+1])
+		}
+	}
+}
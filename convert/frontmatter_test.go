@@ -0,0 +1,79 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/config"
+)
+
+type fakeConverter struct {
+}
+
+func (fakeConverter) Name() string {
+	return "fake"
+}
+
+func (fakeConverter) Version() string {
+	return "1.0"
+}
+
+func (fakeConverter) Convert(src, dst string) ([]string, error) {
+	return []string{dst}, nil
+}
+
+func TestWriteFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.doc")
+	dst := filepath.Join(dir, "a.md")
+	assert.Nil(t, os.WriteFile(src, []byte("abc"), 0644))
+	assert.Nil(t, os.WriteFile(dst, []byte("---\nsubtitle: 副标题\n---\n\n# 正文\n"), 0644))
+
+	assert.Nil(t, writeFrontMatter(src, dst, fakeConverter{}))
+	content, err := os.ReadFile(dst)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "---\nsource: "+filepath.ToSlash(src)+"\n"+
+		"source_sha256: ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n"+
+		"converter: fake\nconverter_version: \"1.0\"\noptions_sha256: "+OptionsSHA256(src)+"\n"+
+		"subtitle: 副标题\n---\n\n# 正文\n", string(content))
+
+	// 重复写入时替换原有元数据
+	assert.Nil(t, writeFrontMatter(src, dst, fakeConverter{}))
+	again, err := os.ReadFile(dst)
+	assert.Nil(t, err)
+	assert.EqualValues(t, string(content), string(again))
+
+	fm, ok, err := ReadFrontMatter(dst)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, "fake", fm.Converter)
+	assert.EqualValues(t, filepath.ToSlash(src), fm.Source)
+}
+
+func TestUpToDate(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	Register(fakeConverter{}, ".fake")
+	defer delete(registry, ".fake")
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.False(t, UpToDate("a.fake"))
+	assert.Nil(t, os.WriteFile("a.fake", []byte("abc"), 0644))
+	assert.Nil(t, os.WriteFile("a.md", []byte("# 正文\n"), 0644))
+	assert.Nil(t, writeFrontMatter("a.fake", "a.md", fakeConverter{}))
+	assert.True(t, UpToDate("a.fake"))
+
+	// 转换配置变化后需要重新转换
+	viper.Set(config.KeyNormalizeLineWidth, 100)
+	assert.False(t, UpToDate("a.fake"))
+	assert.Nil(t, writeFrontMatter("a.fake", "a.md", fakeConverter{}))
+	assert.True(t, UpToDate("a.fake"))
+	viper.Set(config.KeyConverterRules, []map[string]interface{}{{"match": "*.fake", "wrap": "none"}})
+	assert.False(t, UpToDate("a.fake"))
+}
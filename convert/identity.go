@@ -0,0 +1,401 @@
+package convert
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/office"
+	"github.com/zhihanggg/gitdoc-cli/scan"
+)
+
+// IDProperty 在 Office 文档自定义属性中记录文档 ID 的属性名
+const IDProperty = "gitdoc-id"
+
+// Rename 重命名的文档
+type Rename struct {
+	// From 重命名前的文档路径，即旧的 markdown 元数据中记录的 source
+	From string
+	// To 重命名后的文档路径
+	To string
+	// Output 重命名前生成的 markdown
+	Output string
+	// Moved 通过 git mv 移动的原 markdown 及附属文件，只提交部分文档时需要一并提交
+	Moved []string
+}
+
+// newID 生成随机的文档 ID，格式与 UUID v4 相同
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成文档 ID 失败: %v", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// readID 读取文档 ID，不是 Office Open XML 文档或没有 ID 时返回空
+func readID(src string) string {
+	if !ooxml[strings.ToLower(filepath.Ext(src))] {
+		return ""
+	}
+	id, err := office.ReadCustomProperty(src, IDProperty)
+	if err != nil {
+		log.Debug("读取 %s 的文档 ID 失败: %v", src, err)
+	}
+	return id
+}
+
+// ensureID 文档没有 ID 时生成并写入自定义属性，返回文档 ID；文档是复制的、ID 已被另一个仍然存在的文档使用时
+// 重新生成 ID。不是 Office Open XML 文档时返回空
+func ensureID(src string) (string, error) {
+	if !ooxml[strings.ToLower(filepath.Ext(src))] {
+		return "", nil
+	}
+	if id := readID(src); id != "" {
+		other, err := idOwner(id, src)
+		if err != nil {
+			return "", err
+		}
+		if other == "" {
+			return id, nil
+		}
+		log.Info("%s 与 %s 的文档 ID 相同，可能是复制的文档，将重新生成文档 ID", src, other)
+	}
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	if err := office.WriteCustomProperty(src, IDProperty, id); err != nil {
+		return "", err
+	}
+	log.Debug("已为 %s 生成文档 ID %s", src, id)
+	return id, nil
+}
+
+// fileSHA256 返回文件内容的 sha256
+func fileSHA256(p string) (string, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 失败: %v", p, err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// generatedOutputs 返回带有元数据的 markdown 及其元数据
+func generatedOutputs() (map[string]FrontMatter, error) {
+	// 生成的文件写在单独的目录时，只在该目录中查找 markdown
+	opts := scan.OptionsFromConfig()
+	if dir := outputDir(); dir != "" {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil, nil
+		}
+		opts.Roots = []string{dir}
+	}
+	outputs, err := scan.Documents(opts, []string{".md"})
+	if err != nil {
+		return nil, fmt.Errorf("扫描 markdown 文件失败: %v", err)
+	}
+	result := make(map[string]FrontMatter)
+	for _, p := range outputs {
+		fm, ok, err := ReadFrontMatter(p)
+		if err != nil || !ok {
+			continue
+		}
+		result[p] = fm
+	}
+	return result, nil
+}
+
+// orphanOutputs 返回元数据中记录的源文档已不存在的 markdown 及其元数据
+func orphanOutputs() (map[string]FrontMatter, error) {
+	outputs, err := generatedOutputs()
+	if err != nil {
+		return nil, err
+	}
+	for p, fm := range outputs {
+		if _, err := os.Stat(filepath.FromSlash(fm.Source)); !os.IsNotExist(err) {
+			delete(outputs, p)
+		}
+	}
+	return outputs, nil
+}
+
+// idOwner 返回使用文档 ID id 的其他仍然存在的文档，没有时返回空
+func idOwner(id, src string) (string, error) {
+	outputs, err := generatedOutputs()
+	if err != nil {
+		return "", err
+	}
+	self := filepath.ToSlash(filepath.Clean(src))
+	for _, fm := range outputs {
+		if fm.DocID != id || fm.Source == self {
+			continue
+		}
+		if _, err := os.Stat(filepath.FromSlash(fm.Source)); err == nil {
+			return fm.Source, nil
+		}
+	}
+	return "", nil
+}
+
+// similarityThreshold 按内容相似度识别重命名时，两个文档的文字相似度的下限
+const similarityThreshold = 0.8
+
+// DetectRenames 在 docs 中查找重命名的文档：文档还没有生成 markdown，而某个 markdown 元数据中记录的源文档已不存在，
+// 且两者的文档 ID 或内容 sha256 相同；都不相同时，与上次提交的源文档比较文字，相似度最高且不低于 similarityThreshold 的
+// 视为重命名后又做了修改
+func DetectRenames(docs []string) ([]Rename, error) {
+	var fresh []string
+	for _, doc := range docs {
+		if _, err := os.Stat(OutputPath(doc)); os.IsNotExist(err) {
+			fresh = append(fresh, doc)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+
+	orphans, err := orphanOutputs()
+	if err != nil {
+		return nil, err
+	}
+
+	var renames []Rename
+	var unmatched []string
+	for _, doc := range fresh {
+		if len(orphans) == 0 {
+			break
+		}
+		id := readID(doc)
+		sum, err := fileSHA256(doc)
+		if err != nil {
+			return nil, err
+		}
+		matched := false
+		for p, fm := range orphans {
+			if id != "" && fm.DocID == id || fm.SourceSHA256 == sum {
+				renames = append(renames, Rename{From: fm.Source, To: doc, Output: p})
+				delete(orphans, p)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, doc)
+		}
+	}
+	return append(renames, similarRenames(unmatched, orphans)...), nil
+}
+
+// similarRenames 按文字相似度为 docs 匹配 orphans 中的 markdown，源文档的原内容从 HEAD 中读取，没有提交过的无法匹配
+func similarRenames(docs []string, orphans map[string]FrontMatter) []Rename {
+	if len(docs) == 0 || len(orphans) == 0 {
+		return nil
+	}
+	previous := make(map[string]string, len(orphans))
+	for p, fm := range orphans {
+		content, err := git.Show("HEAD", "./"+fm.Source)
+		if err != nil {
+			log.Trace("读取 %s 的原内容失败: %v", fm.Source, err)
+			continue
+		}
+		previous[p] = office.Text(content)
+	}
+	var renames []Rename
+	for _, doc := range docs {
+		content, err := os.ReadFile(doc)
+		if err != nil {
+			continue
+		}
+		text := office.Text(content)
+		best, bestScore := "", similarityThreshold
+		for p, old := range previous {
+			if score := similarity(text, old); score >= bestScore {
+				best, bestScore = p, score
+			}
+		}
+		if best == "" {
+			continue
+		}
+		log.Debug("%s 与 %s 的内容相似度为 %.0f%%", doc, orphans[best].Source, bestScore*100)
+		renames = append(renames, Rename{From: orphans[best].Source, To: doc, Output: best})
+		delete(previous, best)
+	}
+	return renames
+}
+
+// similarity 返回两段文字的相似度，取值 0 到 1：忽略空白后按相邻两个字符组成的片段计算 Dice 系数，适用于中文与英文
+func similarity(a, b string) float64 {
+	grams := func(s string) map[string]int {
+		runes := []rune(strings.Join(strings.Fields(s), ""))
+		result := make(map[string]int, len(runes))
+		for n := 0; n+1 < len(runes); n++ {
+			result[string(runes[n:n+2])]++
+		}
+		return result
+	}
+	x, y := grams(a), grams(b)
+	total := 0
+	for _, c := range x {
+		total += c
+	}
+	for _, c := range y {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	common := 0
+	for g, c := range x {
+		if d := y[g]; d < c {
+			common += d
+		} else {
+			common += c
+		}
+	}
+	return 2 * float64(common) / float64(total)
+}
+
+// outputFiles 返回已存在的生成的 markdown 及批注、csv 等附属文件
+func outputFiles(output string) []string {
+	var files []string
+	if _, err := os.Stat(output); err == nil {
+		files = append(files, output)
+	}
+	base := strings.TrimSuffix(output, filepath.Ext(output))
+	siblings, _ := filepath.Glob(globEscape(base) + ".*")
+	for _, p := range siblings {
+		suffix := strings.TrimPrefix(p, base)
+		if suffix == ".comments.md" || suffix == ".comments.json" || strings.HasSuffix(suffix, ".csv") {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// outputMoves 返回重命名前生成的 markdown 及附属文件与其移动后的路径
+func outputMoves(r Rename) [][2]string {
+	dst := OutputPath(r.To)
+	oldBase := strings.TrimSuffix(r.Output, filepath.Ext(r.Output))
+	newBase := strings.TrimSuffix(dst, filepath.Ext(dst))
+	var moves [][2]string
+	for _, p := range outputFiles(r.Output) {
+		moves = append(moves, [2]string{p, newBase + strings.TrimPrefix(p, oldBase)})
+	}
+	return moves
+}
+
+// MoveOutputs 将重命名前生成的 markdown 及批注、csv 等附属文件移动到新文档对应的位置，
+// 已提交的文件使用 git mv 以便 git log --follow 能够追踪，返回通过 git mv 移动的原文件
+func MoveOutputs(r Rename) ([]string, error) {
+	var moved []string
+	for _, m := range outputMoves(r) {
+		if err := os.MkdirAll(filepath.Dir(m[1]), 0755); err != nil {
+			return nil, fmt.Errorf("创建目录失败: %v", err)
+		}
+		if git.Tracked(m[0]) {
+			if err := git.Move(m[0], m[1]); err != nil {
+				return nil, err
+			}
+			moved = append(moved, m[0])
+			continue
+		}
+		if err := os.Rename(m[0], m[1]); err != nil {
+			return nil, fmt.Errorf("移动 %s 失败: %v", m[0], err)
+		}
+	}
+	return moved, nil
+}
+
+// MoveRenamed 查找 docs 中重命名的文档并移动其生成的文件，返回重命名的文档
+func MoveRenamed(docs []string) ([]Rename, error) {
+	renames, err := DetectRenames(docs)
+	if err != nil {
+		return nil, err
+	}
+	for n, r := range renames {
+		moved, err := MoveOutputs(r)
+		if err != nil {
+			return nil, err
+		}
+		renames[n].Moved = moved
+		log.Info("检测到 %s 重命名为 %s，已移动生成的 markdown", r.From, r.To)
+	}
+	return renames, nil
+}
+
+// RemoveStale 删除源文档已不存在的 markdown 及其附属文件，已提交的文件使用 git rm；
+// sources 为 nil 时处理全部，否则只处理源文档在 sources 中的文件。重命名的文档需要先调用 MoveRenamed
+func RemoveStale(sources []string) ([]Result, error) {
+	results, err := StaleOutputs(sources)
+	if err != nil {
+		return nil, err
+	}
+	for n, res := range results {
+		start := time.Now()
+		for _, p := range res.Outputs {
+			if git.Tracked(p) {
+				err = git.Remove(p)
+			} else if err = os.Remove(p); err != nil {
+				err = fmt.Errorf("删除 %s 失败: %v", p, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		results[n].Duration = time.Since(start)
+		log.Debug("%s 已删除，删除了生成的 %s", res.Source, strings.Join(res.Outputs, ", "))
+	}
+	return results, nil
+}
+
+// StaleOutputs 返回 RemoveStale 将要删除的文件而不实际删除，sources 的含义与 RemoveStale 相同
+func StaleOutputs(sources []string) ([]Result, error) {
+	stale, err := orphanOutputs()
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(sources))
+	for _, src := range sources {
+		wanted[filepath.ToSlash(filepath.Clean(src))] = true
+	}
+	var results []Result
+	for output, fm := range stale {
+		if sources != nil && !wanted[filepath.ToSlash(filepath.Clean(fm.Source))] {
+			continue
+		}
+		files := outputFiles(output)
+		results = append(results, Result{Source: fm.Source, Status: StatusRemoved, Outputs: files, Size: totalSize(files)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Source < results[j].Source
+	})
+	return results, nil
+}
+
+// totalSize 返回文件的总大小，忽略不存在的文件
+func totalSize(files []string) int64 {
+	var size int64
+	for _, p := range files {
+		if info, err := os.Stat(p); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// globEscape 转义 filepath.Glob 的特殊字符
+func globEscape(p string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(p)
+}
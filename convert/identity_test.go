@@ -0,0 +1,224 @@
+package convert
+
+import (
+	"archive/zip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+func TestDetectRenames(t *testing.T) {
+	config.SetDefaults()
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	// old.doc 已重命名为 docs/new.doc，内容不变
+	assert.Nil(t, os.MkdirAll("docs", 0755))
+	assert.Nil(t, os.WriteFile("docs/new.doc", []byte("abc"), 0644))
+	assert.Nil(t, os.WriteFile("old.md", []byte("---\nsource: old.doc\n"+
+		"source_sha256: ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n---\n\n# 正文\n"), 0644))
+	assert.Nil(t, os.WriteFile("old.comments.md", []byte("# 批注\n"), 0644))
+	// other.doc 仍然存在，不会被当作重命名
+	assert.Nil(t, os.WriteFile("other.doc", []byte("abc"), 0644))
+	assert.Nil(t, os.WriteFile("other.md", []byte("---\nsource: other.doc\n"+
+		"source_sha256: ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n---\n"), 0644))
+
+	renames, err := DetectRenames([]string{"docs/new.doc", "other.doc"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Rename{{From: "old.doc", To: "docs/new.doc", Output: "old.md"}}, renames)
+
+	moved, err := MoveOutputs(renames[0])
+	assert.Nil(t, err)
+	assert.Empty(t, moved)
+	for _, p := range []string{"docs/new.md", "docs/new.comments.md"} {
+		_, err := os.Stat(filepath.FromSlash(p))
+		assert.Nil(t, err, p)
+	}
+	_, err = os.Stat("old.md")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDetectRenamesOutputDir(t *testing.T) {
+	config.SetDefaults()
+	viper.Set(config.KeyConverterOutputDir, ".gitdoc/md")
+	defer viper.Set(config.KeyConverterOutputDir, "")
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.Equal(t, filepath.FromSlash(".gitdoc/md/docs/new.md"), OutputPath("docs/new.doc"))
+	assert.Equal(t, []string{filepath.FromSlash(".gitdoc/md/media")}, mediaDirs())
+
+	assert.Nil(t, os.MkdirAll("docs", 0755))
+	assert.Nil(t, os.WriteFile("docs/new.doc", []byte("abc"), 0644))
+	assert.Nil(t, os.MkdirAll(".gitdoc/md", 0755))
+	assert.Nil(t, os.WriteFile(".gitdoc/md/old.md", []byte("---\nsource: old.doc\n"+
+		"source_sha256: ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n---\n"), 0644))
+
+	renames, err := DetectRenames([]string{"docs/new.doc"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Rename{{From: "old.doc", To: "docs/new.doc", Output: filepath.FromSlash(".gitdoc/md/old.md")}}, renames)
+
+	_, err = MoveOutputs(renames[0])
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.FromSlash(".gitdoc/md/docs/new.md"))
+	assert.Nil(t, err)
+}
+
+func TestRemoveStale(t *testing.T) {
+	config.SetDefaults()
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	for _, name := range []string{"gone", "kept"} {
+		assert.Nil(t, os.WriteFile(name+".md", []byte("---\nsource: "+name+".doc\n---\n"), 0644))
+	}
+	assert.Nil(t, os.WriteFile("gone.comments.md", []byte("# 批注\n"), 0644))
+	assert.Nil(t, os.WriteFile("kept.doc", []byte("abc"), 0644))
+
+	results, err := RemoveStale([]string{})
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+
+	results, err = RemoveStale(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "gone.doc", results[0].Source)
+	assert.Equal(t, StatusRemoved, results[0].Status)
+	assert.Equal(t, []string{"gone.md", "gone.comments.md"}, results[0].Outputs)
+	for _, p := range results[0].Outputs {
+		_, err := os.Stat(p)
+		assert.True(t, os.IsNotExist(err), p)
+	}
+	_, err = os.Stat("kept.md")
+	assert.Nil(t, err)
+}
+
+func TestDetectRenamesSimilar(t *testing.T) {
+	config.SetDefaults()
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, exec.Command("git", "init", "-q", dir).Run())
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	// 已提交的 old.doc 重命名为 new.doc 后又修改了一句话
+	text := "甲方委托乙方开发文档管理工具，乙方应在三十日内交付全部功能并提供一年的维护服务。"
+	assert.Nil(t, os.WriteFile("old.doc", []byte(text+"付款方式为银行转账。"), 0644))
+	assert.Nil(t, os.WriteFile("unrelated.doc", []byte("会议纪要：讨论下季度的招聘计划。"), 0644))
+	assert.Nil(t, exec.Command("git", "add", "-A").Run())
+	output, err := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-qm", "init").CombinedOutput()
+	assert.Nil(t, err, string(output))
+	for _, name := range []string{"old", "unrelated"} {
+		assert.Nil(t, os.WriteFile(name+".md", []byte("---\nsource: "+name+".doc\nsource_sha256: x\n---\n"), 0644))
+		assert.Nil(t, os.Remove(name+".doc"))
+	}
+	assert.Nil(t, os.WriteFile("new.doc", []byte(text+"付款方式为现金。"), 0644))
+	assert.Nil(t, os.WriteFile("other.doc", []byte("完全不同的另一份文档内容。"), 0644))
+
+	renames, err := DetectRenames([]string{"new.doc", "other.doc"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Rename{{From: "old.doc", To: "new.doc", Output: "old.md"}}, renames)
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, similarity("文档 管理", "文档管理"))
+	assert.Equal(t, 0.0, similarity("", ""))
+	assert.Equal(t, 0.0, similarity("abc", "xyz"))
+	// ab bc cd de ef 与 ab bc cd dx xy 有 3 个相同的片段
+	assert.InDelta(t, 0.6, similarity("abcdef", "abcdxy"), 0.001)
+}
+
+func TestEnsureIDCopy(t *testing.T) {
+	config.SetDefaults()
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	writeDocx := func(name string) {
+		f, err := os.Create(name)
+		assert.Nil(t, err)
+		w := zip.NewWriter(f)
+		for part, content := range map[string]string{
+			"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+			"_rels/.rels":         `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`,
+			"word/document.xml":   `<w:document/>`,
+		} {
+			fw, err := w.Create(part)
+			assert.Nil(t, err)
+			_, err = fw.Write([]byte(content))
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, w.Close())
+		assert.Nil(t, f.Close())
+	}
+	writeDocx("a.docx")
+	id, err := ensureID("a.docx")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, id)
+	assert.Nil(t, os.WriteFile("a.md", []byte("---\nsource: a.docx\ndoc_id: "+id+"\n---\n"), 0644))
+	again, err := ensureID("a.docx")
+	assert.Nil(t, err)
+	assert.Equal(t, id, again)
+
+	// 复制的文档带有相同的 ID，会重新生成
+	content, err := os.ReadFile("a.docx")
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile("b.docx", content, 0644))
+	copied, err := ensureID("b.docx")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, copied)
+	assert.NotEqual(t, id, copied)
+	value, err := office.ReadCustomProperty("b.docx", IDProperty)
+	assert.Nil(t, err)
+	assert.Equal(t, copied, value)
+}
+
+func TestRenameThenConvert(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	Register(fakeConverter{}, ".fake")
+	defer delete(registry, ".fake")
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, exec.Command("git", "init", "-q", dir).Run())
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.Nil(t, os.WriteFile("old.fake", []byte("abc"), 0644))
+	assert.Nil(t, os.WriteFile("old.md", []byte("# 正文\n"), 0644))
+	assert.Nil(t, writeFrontMatter("old.fake", "old.md", fakeConverter{}))
+	assert.Nil(t, exec.Command("git", "add", "-A").Run())
+	output, err := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-qm", "init").CombinedOutput()
+	assert.Nil(t, err, string(output))
+
+	// 与 commit 相同的顺序：移动生成的 markdown、转换、删除已删除文档生成的文件
+	assert.Nil(t, os.Rename("old.fake", "new.fake"))
+	renames, err := MoveRenamed([]string{"new.fake"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(renames))
+	results := Files([]string{"new.fake"}, false)
+	assert.Equal(t, StatusConverted, results[0].Status)
+	removed, err := RemoveStale(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, removed)
+
+	fm, ok, err := ReadFrontMatter("new.md")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "new.fake", fm.Source)
+}
@@ -0,0 +1,79 @@
+package convert
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// imageLinkRE markdown 图片链接 ![alt](path "title")
+	imageLinkRE = regexp.MustCompile(`!\[[^\]]*\]\(\s*(?:<([^>]+)>|([^)\s]+))`)
+	// imageTagRE html 图片标签 <img src="path">
+	imageTagRE = regexp.MustCompile(`<img\b[^>]*\bsrc="([^"]+)"`)
+)
+
+// MediaFiles 返回 outputs 中的 markdown 引用的、位于媒体文件目录下的文件，已去重
+func MediaFiles(outputs []string) []string {
+	dirs := mediaDirs()
+	seen := make(map[string]bool)
+	var files []string
+	for _, output := range outputs {
+		if !strings.EqualFold(filepath.Ext(output), ".md") {
+			continue
+		}
+		content, err := os.ReadFile(output)
+		if err != nil {
+			continue
+		}
+		var links []string
+		for _, m := range imageLinkRE.FindAllStringSubmatch(string(content), -1) {
+			links = append(links, m[1]+m[2])
+		}
+		for _, m := range imageTagRE.FindAllStringSubmatch(string(content), -1) {
+			links = append(links, m[1])
+		}
+		for _, link := range links {
+			p, ok := resolveMedia(output, link, dirs)
+			if ok && !seen[p] {
+				seen[p] = true
+				files = append(files, p)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// resolveMedia 将 markdown 中的链接解析为媒体文件路径，pandoc 生成的链接可能相对于当前目录或 markdown 所在目录
+func resolveMedia(output, link string, dirs []string) (string, bool) {
+	if strings.Contains(link, "://") || strings.HasPrefix(link, "data:") {
+		return "", false
+	}
+	if unescaped, err := url.PathUnescape(link); err == nil {
+		link = unescaped
+	}
+	link = filepath.FromSlash(link)
+	for _, p := range []string{filepath.Clean(link), filepath.Join(filepath.Dir(output), link)} {
+		if !inDirs(p, dirs) {
+			continue
+		}
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// inDirs 路径 p 是否位于 dirs 中的某个目录下
+func inDirs(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, p); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/config"
+)
+
+func TestMediaFiles(t *testing.T) {
+	config.SetDefaults()
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	media := "media"
+	assert.Nil(t, os.MkdirAll(media, 0755))
+	for _, name := range []string{"a.png", "b c.png", "unused.png"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(media, name), []byte("png"), 0644))
+	}
+	assert.Nil(t, os.WriteFile("doc.md", []byte("![](media/a.png)\n"+
+		"![图](<media/b c.png>)\n<img src=\"media/a.png\" />\n"+
+		"![](https://example.com/x.png)\n![](other/x.png)\n"), 0644))
+
+	assert.Equal(t, []string{filepath.Join(media, "a.png"), filepath.Join(media, "b c.png")}, MediaFiles([]string{"doc.md"}))
+}
@@ -0,0 +1,127 @@
+package convert
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/markdown"
+	"github.com/zhihanggg/gitdoc-cli/office"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// pandoc 使用 pandoc 转换 doc/docx/odt/rtf 等文字处理文档
+type pandoc struct {
+}
+
+// Name 转换器名称
+func (pandoc) Name() string {
+	return "pandoc"
+}
+
+var (
+	pandocVersion     string
+	pandocVersionOnce sync.Once
+)
+
+// Version 返回 pandoc 的版本，如 3.1.2，获取失败时返回 unknown
+func (pandoc) Version() string {
+	pandocVersionOnce.Do(func() {
+		pandocVersion = "unknown"
+		output, err := utils.ExecCmd("pandoc --version")
+		if err != nil {
+			log.Debug("获取 pandoc 版本失败: %v", err)
+			return
+		}
+		// 第一行格式为: pandoc 3.1.2
+		line, _, _ := strings.Cut(output, "\n")
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			pandocVersion = fields[len(fields)-1]
+		}
+	})
+	return pandocVersion
+}
+
+// Convert 转换文档
+func (pandoc) Convert(src, dst string) ([]string, error) {
+	opts := OptionsFor(src)
+	if err := utils.ConvertDocToMarkdown(src, dst, pandocArgs(opts)...); err != nil {
+		return nil, err
+	}
+	if err := normalize(dst); err != nil {
+		return nil, err
+	}
+	outputs := []string{dst}
+	if exportComments(src, opts) {
+		files, err := writeComments(src, dst, opts.Comments)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, files...)
+	}
+	return outputs, nil
+}
+
+// Plan 返回转换文档将要生成的文件，导出批注且文档有批注时包括批注文件
+func (pandoc) Plan(src, dst string) ([]string, error) {
+	opts := OptionsFor(src)
+	outputs := []string{dst}
+	if exportComments(src, opts) {
+		comments, err := office.ReadComments(src)
+		if err != nil {
+			return nil, err
+		}
+		if len(comments) > 0 {
+			outputs = append(outputs, commentsPath(dst, opts.Comments))
+		}
+	}
+	return outputs, nil
+}
+
+// exportComments 是否导出文档的批注，只支持 docx
+func exportComments(src string, opts Options) bool {
+	return opts.Comments != CommentsNone && strings.EqualFold(filepath.Ext(src), ".docx")
+}
+
+// normalize 按配置规范化 pandoc 生成的 markdown，减少 pandoc 版本差异与细微编辑带来的 diff
+func normalize(path string) error {
+	opts := markdown.Options{
+		Passes:          viper.GetStringSlice(config.KeyNormalizePasses),
+		LineWidth:       viper.GetInt(config.KeyNormalizeLineWidth),
+		SentencePerLine: viper.GetBool(config.KeyNormalizeSentencePerLine),
+	}
+	if len(opts.Passes) == 0 {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %v", path, err)
+	}
+	normalized, err := markdown.Normalize(string(content), opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(normalized), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", path, err)
+	}
+	return nil
+}
+
+// pandocArgs 根据转换参数生成 pandoc 参数
+func pandocArgs(opts Options) []string {
+	args := []string{
+		"--extract-media=" + utils.ShellQuote(opts.ExtractMedia),
+		"-t", opts.Format,
+		"--wrap=" + opts.Wrap,
+		"--track-changes=" + opts.TrackChanges,
+	}
+	for _, filter := range opts.Filters {
+		args = append(args, "--lua-filter="+utils.ShellQuote(filter))
+	}
+	return args
+}
@@ -0,0 +1,192 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/markdown"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// Status 文档的转换结果
+type Status string
+
+const (
+	// StatusConverted 已转换
+	StatusConverted Status = "converted"
+	// StatusSkipped 文档未变更，沿用之前生成的 markdown
+	StatusSkipped Status = "skipped"
+	// StatusFailed 转换失败
+	StatusFailed Status = "failed"
+	// StatusRemoved 文档已删除，删除了生成的文件
+	StatusRemoved Status = "removed"
+)
+
+// statusNames 表格中显示的状态名称
+var statusNames = map[Status]string{
+	StatusConverted: "已转换",
+	StatusSkipped:   "已跳过",
+	StatusFailed:    "失败",
+	StatusRemoved:   "已删除",
+}
+
+// Result 单个文档的转换结果
+type Result struct {
+	Source   string        `json:"source"`
+	Status   Status        `json:"status"`
+	Outputs  []string      `json:"outputs,omitempty"`
+	Size     int64         `json:"size"`
+	Duration time.Duration `json:"-"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// MarshalJSON 耗时以毫秒输出
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		DurationMS int64 `json:"duration_ms"`
+	}{result(r), r.Duration.Milliseconds()})
+}
+
+// Report 一次转换的报告
+type Report struct {
+	Started  time.Time
+	Duration time.Duration
+	Results  []Result
+}
+
+// NewReport 创建从当前时间开始的转换报告
+func NewReport() *Report {
+	return &Report{Started: time.Now()}
+}
+
+// Add 添加文档的转换结果
+func (r *Report) Add(results ...Result) {
+	r.Results = append(r.Results, results...)
+}
+
+// Finish 记录转换的总耗时
+func (r *Report) Finish() {
+	r.Duration = time.Since(r.Started)
+}
+
+// Count 返回转换结果为 status 的文档数
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Outputs 返回已转换与已跳过的文档生成的文件
+func (r *Report) Outputs() []string {
+	var outputs []string
+	for _, res := range r.Results {
+		if res.Status == StatusConverted || res.Status == StatusSkipped {
+			outputs = append(outputs, res.Outputs...)
+		}
+	}
+	return outputs
+}
+
+// Removed 返回删除的文件
+func (r *Report) Removed() []string {
+	var removed []string
+	for _, res := range r.Results {
+		if res.Status == StatusRemoved {
+			removed = append(removed, res.Outputs...)
+		}
+	}
+	return removed
+}
+
+// Err 有文档转换失败时返回错误
+func (r *Report) Err() error {
+	for _, res := range r.Results {
+		if res.Status == StatusFailed {
+			return fmt.Errorf("有 %d 个文档转换失败，第一个为 %s: %s", r.Count(StatusFailed), res.Source, res.Error)
+		}
+	}
+	return nil
+}
+
+// Summary 返回一行转换结果汇总
+func (r *Report) Summary() string {
+	return fmt.Sprintf("转换 %d 个，跳过 %d 个，失败 %d 个，删除 %d 个，耗时 %s",
+		r.Count(StatusConverted), r.Count(StatusSkipped), r.Count(StatusFailed), r.Count(StatusRemoved),
+		r.Duration.Round(time.Millisecond))
+}
+
+// Table 返回表格形式的转换结果，按显示宽度对齐中文
+func (r *Report) Table() string {
+	rows := [][]string{{"状态", "文档", "耗时", "大小", "输出"}}
+	for _, res := range r.Results {
+		detail := strings.Join(res.Outputs, ", ")
+		if res.Status == StatusFailed {
+			detail = res.Error
+		}
+		rows = append(rows, []string{statusNames[res.Status], res.Source,
+			res.Duration.Round(time.Millisecond).String(), utils.FormatSize(res.Size), detail})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for n, cell := range row {
+			if w := markdown.DisplayWidth(cell); w > widths[n] {
+				widths[n] = w
+			}
+		}
+	}
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var sb strings.Builder
+		for n, cell := range row {
+			sb.WriteString(cell)
+			if n < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[n]-markdown.DisplayWidth(cell)+2))
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Print 打印转换结果表格与汇总，没有转换任何文档时只打印汇总
+func (r *Report) Print() {
+	if len(r.Results) > 0 {
+		log.Normal("%s", r.Table())
+	}
+	log.Info("%s", r.Summary())
+}
+
+// WriteJSON 将转换报告以 json 格式写入文件 p
+func (r *Report) WriteJSON(p string) error {
+	summary := make(map[Status]int)
+	for _, status := range []Status{StatusConverted, StatusSkipped, StatusFailed, StatusRemoved} {
+		summary[status] = r.Count(status)
+	}
+	results := r.Results
+	if results == nil {
+		results = []Result{}
+	}
+	content, err := json.MarshalIndent(struct {
+		Started    time.Time      `json:"started"`
+		DurationMS int64          `json:"duration_ms"`
+		Summary    map[Status]int `json:"summary"`
+		Documents  []Result       `json:"documents"`
+	}{r.Started, r.Duration.Milliseconds(), summary, results}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成转换报告失败: %v", err)
+	}
+	if err := os.WriteFile(p, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入转换报告 %s 失败: %v", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package convert
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	report := NewReport()
+	report.Add(
+		Result{Source: "a.docx", Status: StatusConverted, Outputs: []string{"a.md"}, Size: 2048, Duration: 1500 * time.Millisecond},
+		Result{Source: "b.docx", Status: StatusSkipped, Outputs: []string{"b.md"}},
+		Result{Source: "c.docx", Status: StatusFailed, Error: "pandoc 执行失败"},
+		Result{Source: "d.docx", Status: StatusRemoved, Outputs: []string{"d.md"}},
+	)
+	report.Finish()
+
+	assert.Equal(t, 1, report.Count(StatusConverted))
+	assert.Equal(t, []string{"a.md", "b.md"}, report.Outputs())
+	assert.Equal(t, []string{"d.md"}, report.Removed())
+	assert.EqualError(t, report.Err(), "有 1 个文档转换失败，第一个为 c.docx: pandoc 执行失败")
+	assert.True(t, strings.HasPrefix(report.Summary(), "转换 1 个，跳过 1 个，失败 1 个，删除 1 个，耗时 "))
+	assert.Contains(t, report.Table(), "状态    文档    耗时  大小   输出\n已转换  a.docx  1.5s  2.0KB  a.md\n")
+
+	p := filepath.Join(t.TempDir(), "report.json")
+	assert.Nil(t, report.WriteJSON(p))
+	content, err := os.ReadFile(p)
+	assert.Nil(t, err)
+	var decoded struct {
+		Summary   map[string]int           `json:"summary"`
+		Documents []map[string]interface{} `json:"documents"`
+	}
+	assert.Nil(t, json.Unmarshal(content, &decoded))
+	assert.Equal(t, map[string]int{"converted": 1, "skipped": 1, "failed": 1, "removed": 1}, decoded.Summary)
+	assert.Equal(t, float64(1500), decoded.Documents[0]["duration_ms"])
+	assert.Equal(t, float64(2048), decoded.Documents[0]["size"])
+	assert.Equal(t, "pandoc 执行失败", decoded.Documents[2]["error"])
+}
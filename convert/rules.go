@@ -0,0 +1,166 @@
+package convert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/scan"
+)
+
+// Rule converter.rules 中的一条规则，为空的字段沿用全局配置
+type Rule struct {
+	Match        string   `mapstructure:"match"`
+	Format       string   `mapstructure:"format"`
+	Wrap         string   `mapstructure:"wrap"`
+	Filters      []string `mapstructure:"filters"`
+	TrackChanges string   `mapstructure:"track_changes"`
+	ExtractMedia string   `mapstructure:"extract_media"`
+	Comments     string   `mapstructure:"comments"`
+}
+
+// Options 转换单个文档时 pandoc 使用的参数
+type Options struct {
+	Format       string
+	Wrap         string
+	Filters      []string
+	TrackChanges string
+	ExtractMedia string
+	Comments     string
+}
+
+// rules 读取 converter.rules
+func rules() []Rule {
+	var list []Rule
+	if err := viper.UnmarshalKey(config.KeyConverterRules, &list); err != nil {
+		log.Warn("读取 %s 失败: %v", config.KeyConverterRules, err)
+		return nil
+	}
+	return list
+}
+
+// OptionsFor 返回文档 src 的转换参数：先取全局配置，再依次应用匹配的规则
+func OptionsFor(src string) Options {
+	opts := Options{
+		Format:       viper.GetString(config.KeyConverterFormat),
+		Wrap:         viper.GetString(config.KeyConverterWrap),
+		Filters:      viper.GetStringSlice(config.KeyConverterFilters),
+		TrackChanges: viper.GetString(config.KeyConverterTrackChanges),
+		ExtractMedia: viper.GetString(config.KeyConverterExtractMedia),
+		Comments:     viper.GetString(config.KeyConverterComments),
+	}
+	for _, rule := range rules() {
+		m := scan.NewMatcher()
+		m.AddPatterns("", []string{rule.Match})
+		if !m.Ignored(src) {
+			continue
+		}
+		log.Debug("%s 匹配转换规则 %s", src, rule.Match)
+		if rule.Format != "" {
+			opts.Format = rule.Format
+		}
+		if rule.Wrap != "" {
+			opts.Wrap = rule.Wrap
+		}
+		if rule.Filters != nil {
+			opts.Filters = rule.Filters
+		}
+		if rule.TrackChanges != "" {
+			opts.TrackChanges = rule.TrackChanges
+		}
+		if rule.ExtractMedia != "" {
+			opts.ExtractMedia = rule.ExtractMedia
+		}
+		if rule.Comments != "" {
+			opts.Comments = rule.Comments
+		}
+	}
+	if dir := outputDir(); dir != "" {
+		opts.ExtractMedia = filepath.Join(dir, opts.ExtractMedia)
+	}
+	return opts
+}
+
+// OptionsSHA256 返回影响文档 src 转换结果的全部配置的 sha256，包括匹配的转换规则、规范化、幻灯片、表格、
+// 清理个人信息与文档 ID 的配置以及 lua filter 文件的内容；记录在元数据中，配置变化后文档会重新转换
+func OptionsSHA256(src string) string {
+	opts := OptionsFor(src)
+	filters := make(map[string]string, len(opts.Filters))
+	for _, f := range opts.Filters {
+		// filter 文件不存在时由转换报错，这里只记录路径
+		if sum, err := fileSHA256(f); err == nil {
+			filters[f] = sum
+		}
+	}
+	content, _ := json.Marshal(struct {
+		Options         Options
+		FilterSHA256    map[string]string
+		NormalizePasses []string
+		LineWidth       int
+		SentencePerLine bool
+		SlideNotes      bool
+		SheetFormat     string
+		Scrub           bool
+		Pseudonym       string
+		Identity        bool
+	}{
+		Options:         opts,
+		FilterSHA256:    filters,
+		NormalizePasses: viper.GetStringSlice(config.KeyNormalizePasses),
+		LineWidth:       viper.GetInt(config.KeyNormalizeLineWidth),
+		SentencePerLine: viper.GetBool(config.KeyNormalizeSentencePerLine),
+		SlideNotes:      viper.GetBool(config.KeyConverterSlideNotes),
+		SheetFormat:     viper.GetString(config.KeyConverterSheetFormat),
+		Scrub:           viper.GetBool(config.KeyScrubEnabled),
+		Pseudonym:       viper.GetString(config.KeyScrubPseudonym),
+		Identity:        viper.GetBool(config.KeyIdentityEnabled),
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// MediaDirs 返回全局配置与各条规则中已存在的媒体文件目录
+func MediaDirs() []string {
+	var result []string
+	for _, dir := range mediaDirs() {
+		if _, err := os.Stat(dir); err == nil {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// MediaPatterns 返回匹配全部媒体文件的 .gitattributes 路径模式，如 media/**
+func MediaPatterns() []string {
+	dirs := mediaDirs()
+	patterns := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		patterns = append(patterns, filepath.ToSlash(dir)+"/**")
+	}
+	return patterns
+}
+
+// mediaDirs 返回全局与各条规则配置的媒体文件目录，配置了 converter.output_dir 时位于该目录下，已去重
+func mediaDirs() []string {
+	dirs := []string{viper.GetString(config.KeyConverterExtractMedia)}
+	for _, rule := range rules() {
+		if rule.ExtractMedia != "" {
+			dirs = append(dirs, rule.ExtractMedia)
+		}
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		dir = filepath.Join(outputDir(), dir, "media")
+		if !seen[dir] {
+			seen[dir] = true
+			result = append(result, dir)
+		}
+	}
+	return result
+}
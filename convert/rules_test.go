@@ -0,0 +1,32 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/config"
+)
+
+func TestOptionsFor(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	viper.Set(config.KeyConverterRules, []interface{}{
+		map[string]interface{}{"match": "contracts/**", "format": "gfm", "track_changes": "all"},
+		map[string]interface{}{"match": "contracts/draft/*.docx", "filters": []interface{}{"filters/draft.lua"}, "extract_media": "assets"},
+	})
+
+	opts := OptionsFor("notes/a.docx")
+	assert.EqualValues(t, Options{Format: "markdown", Wrap: "auto", Filters: []string{}, TrackChanges: "accept", ExtractMedia: ".", Comments: "none"}, opts)
+
+	opts = OptionsFor("./contracts/a.docx")
+	assert.EqualValues(t, "gfm", opts.Format)
+	assert.EqualValues(t, "all", opts.TrackChanges)
+	assert.EqualValues(t, ".", opts.ExtractMedia)
+
+	opts = OptionsFor("contracts/draft/b.docx")
+	assert.EqualValues(t, "gfm", opts.Format)
+	assert.EqualValues(t, []string{"filters/draft.lua"}, opts.Filters)
+	assert.EqualValues(t, "assets", opts.ExtractMedia)
+	assert.Contains(t, pandocArgs(opts), "--lua-filter='filters/draft.lua'")
+}
@@ -0,0 +1,204 @@
+package convert
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/entity/version"
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+const (
+	// SheetFormatMarkdown 每个工作表生成一个 markdown 表格
+	SheetFormatMarkdown = "markdown"
+	// SheetFormatCSV 每个工作表生成一个 csv 文件
+	SheetFormatCSV = "csv"
+)
+
+// sheets 将电子表格转换为 markdown 表格或 csv
+type sheets struct {
+}
+
+// Name 转换器名称
+func (sheets) Name() string {
+	return "sheets"
+}
+
+// Version 内置转换器的版本与 gitdoc-cli 相同
+func (sheets) Version() string {
+	return version.Version
+}
+
+// Convert 转换文档
+func (sheets) Convert(src, dst string) ([]string, error) {
+	list, err := office.ReadSheets(src)
+	if err != nil {
+		return nil, err
+	}
+	title := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	if viper.GetString(config.KeyConverterSheetFormat) == SheetFormatCSV {
+		csvFiles, err := writeCSV(dst, list)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(dst, []byte(renderIndex(title, list, csvFiles)), 0644); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", dst, err)
+		}
+		return append([]string{dst}, csvFiles...), nil
+	}
+	if err := os.WriteFile(dst, []byte(renderSheets(title, list)), 0644); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %v", dst, err)
+	}
+	return []string{dst}, nil
+}
+
+// Plan 返回转换文档将要生成的文件，csv 模式下包括每个工作表的 csv 文件
+func (sheets) Plan(src, dst string) ([]string, error) {
+	if viper.GetString(config.KeyConverterSheetFormat) != SheetFormatCSV {
+		return []string{dst}, nil
+	}
+	list, err := office.ReadSheets(src)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{dst}, csvPaths(dst, list)...), nil
+}
+
+// renderIndex 生成 csv 模式下的索引 markdown，列出每个工作表对应的 csv 文件
+func renderIndex(title string, list []office.Sheet, csvFiles []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", escapeInline(title))
+	for i, s := range list {
+		name := filepath.Base(csvFiles[i])
+		fmt.Fprintf(&sb, "- [%s](<%s>)\n", escapeInline(s.Name), name)
+	}
+	return sb.String()
+}
+
+// renderSheets 生成电子表格的 markdown，每个工作表一个章节，首行作为表头
+func renderSheets(title string, list []office.Sheet) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", escapeInline(title))
+	for _, s := range list {
+		heading := escapeInline(s.Name)
+		if s.Hidden {
+			heading += " (隐藏)"
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", heading)
+		if len(s.Rows) == 0 {
+			sb.WriteString("_(空)_\n")
+			continue
+		}
+		for i, row := range s.Rows {
+			writeRow(&sb, row)
+			if i == 0 {
+				writeRow(&sb, separator(len(row)))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func writeRow(sb *strings.Builder, row []string) {
+	cells := make([]string, 0, len(row))
+	for _, cell := range row {
+		cell = strings.ReplaceAll(escapeInline(cell), "|", `\|`)
+		cell = strings.ReplaceAll(strings.ReplaceAll(cell, "\r\n", "\n"), "\n", "<br>")
+		cells = append(cells, cell)
+	}
+	sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+}
+
+func separator(n int) []string {
+	row := make([]string, n)
+	for i := range row {
+		row[i] = "---"
+	}
+	return row
+}
+
+// csvLinkRE 匹配 csv 模式下索引 markdown 中指向 csv 文件的链接
+var csvLinkRE = regexp.MustCompile(`\]\(<([^>]+\.csv)>\)`)
+
+// writeCSV 每个工作表生成一个 csv 文件，文件名为 <文档名>.<工作表名>.csv，返回生成的 csv 文件；
+// 上次转换生成、本次不再生成的 csv 文件（工作表已删除或重命名）会被删除
+func writeCSV(dst string, list []office.Sheet) ([]string, error) {
+	previous := indexedCSV(dst)
+	outputs := csvPaths(dst, list)
+	for i, s := range list {
+		p := outputs[i]
+		f, err := os.Create(p)
+		if err != nil {
+			return nil, fmt.Errorf("创建 %s 失败: %v", p, err)
+		}
+		w := csv.NewWriter(f)
+		_ = w.WriteAll(s.Rows)
+		err = w.Error()
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", p, err)
+		}
+	}
+	current := make(map[string]bool, len(outputs))
+	for _, p := range outputs {
+		current[p] = true
+	}
+	for _, p := range previous {
+		if current[p] {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("删除 %s 失败: %v", p, err)
+		}
+	}
+	return outputs, nil
+}
+
+// indexedCSV 返回上次转换生成的索引 markdown 中列出的 <文档名>.*.csv 文件；只删除索引中列出的文件，
+// 避免误删名称以 <文档名>. 开头的其他文档生成的 csv
+func indexedCSV(dst string) []string {
+	content, err := os.ReadFile(dst)
+	if err != nil {
+		return nil
+	}
+	prefix := strings.TrimSuffix(filepath.Base(dst), filepath.Ext(dst)) + "."
+	var paths []string
+	for _, m := range csvLinkRE.FindAllStringSubmatch(string(content), -1) {
+		name := m[1]
+		if strings.HasPrefix(name, prefix) && filepath.Base(name) == name {
+			paths = append(paths, filepath.Join(filepath.Dir(dst), name))
+		}
+	}
+	return paths
+}
+
+// csvPaths 返回每个工作表对应的 csv 文件路径，工作表名称替换字符后相同时依次加上 _2、_3 等后缀
+func csvPaths(dst string, list []office.Sheet) []string {
+	base := strings.TrimSuffix(dst, filepath.Ext(dst))
+	paths := make([]string, 0, len(list))
+	used := make(map[string]bool, len(list))
+	for _, s := range list {
+		name := sanitize(s.Name)
+		// 忽略大小写，避免在大小写不敏感的文件系统上互相覆盖
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s_%d", sanitize(s.Name), n)
+		}
+		used[strings.ToLower(name)] = true
+		paths = append(paths, base+"."+name+".csv")
+	}
+	return paths
+}
+
+// sanitize 替换文件名中不允许出现的字符
+func sanitize(name string) string {
+	return strings.NewReplacer("/", "_", `\`, "_", ":", "_", "*", "_", "?", "_",
+		`"`, "_", "<", "_", ">", "_", "|", "_").Replace(name)
+}
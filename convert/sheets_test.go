@@ -0,0 +1,41 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+func TestWriteCSV(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "a.md")
+	// 其他文档生成的 csv 不会被删除
+	other := filepath.Join(dir, "a.b.Sheet1.csv")
+	assert.Nil(t, os.WriteFile(other, []byte("x\n"), 0644))
+
+	// 替换字符后同名的工作表加上后缀，不会互相覆盖
+	list := []office.Sheet{
+		{Name: "a/b", Rows: [][]string{{"1"}}},
+		{Name: "a:b", Rows: [][]string{{"2"}}},
+		{Name: "Old", Rows: [][]string{{"3"}}},
+	}
+	outputs, err := writeCSV(dst, list)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.a_b.csv"), filepath.Join(dir, "a.a_b_2.csv"),
+		filepath.Join(dir, "a.Old.csv")}, outputs)
+	content, err := os.ReadFile(outputs[1])
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", string(content))
+	assert.Nil(t, os.WriteFile(dst, []byte(renderIndex("a", list, outputs)), 0644))
+
+	// 删除或重命名的工作表之前生成的 csv 会被删除
+	outputs, err = writeCSV(dst, []office.Sheet{{Name: "New", Rows: [][]string{{"4"}}}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.New.csv")}, outputs)
+	files, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.New.csv"), other}, files)
+}
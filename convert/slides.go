@@ -0,0 +1,81 @@
+package convert
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/entity/version"
+	"github.com/zhihanggg/gitdoc-cli/office"
+)
+
+// slides 将演示文稿转换为 markdown，每页幻灯片一个章节
+type slides struct {
+}
+
+// Name 转换器名称
+func (slides) Name() string {
+	return "slides"
+}
+
+// Version 内置转换器的版本与 gitdoc-cli 相同
+func (slides) Version() string {
+	return version.Version
+}
+
+// Convert 转换文档
+func (slides) Convert(src, dst string) ([]string, error) {
+	list, err := office.ReadSlides(src)
+	if err != nil {
+		return nil, err
+	}
+	content := renderSlides(strings.TrimSuffix(filepath.Base(src), filepath.Ext(src)), list,
+		viper.GetBool(config.KeyConverterSlideNotes))
+	if err := os.WriteFile(dst, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %v", dst, err)
+	}
+	return []string{dst}, nil
+}
+
+// renderSlides 生成演示文稿的 markdown
+func renderSlides(title string, list []office.Slide, notes bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", escapeInline(title))
+	for _, s := range list {
+		heading := fmt.Sprintf("幻灯片 %d", s.Index)
+		if s.Title != "" {
+			heading += ": " + escapeInline(s.Title)
+		}
+		if s.Hidden {
+			heading += " (隐藏)"
+		}
+		fmt.Fprintf(&sb, "\n## %s\n", heading)
+		if len(s.Paragraphs) > 0 {
+			sb.WriteString("\n")
+			writeList(&sb, s.Paragraphs)
+		}
+		if notes && len(s.Notes) > 0 {
+			sb.WriteString("\n### 备注\n\n")
+			for _, p := range s.Notes {
+				sb.WriteString(escapeInline(p.Text) + "\n\n")
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// writeList 按段落层级输出 markdown 列表
+func writeList(sb *strings.Builder, paragraphs []office.Paragraph) {
+	for _, p := range paragraphs {
+		sb.WriteString(strings.Repeat("  ", p.Level) + "- " + escapeInline(p.Text) + "\n")
+	}
+}
+
+// escapeInline 转义会被 markdown 解析为格式的字符
+func escapeInline(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`, "[", `\[`)
+	return replacer.Replace(s)
+}
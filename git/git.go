@@ -0,0 +1,317 @@
+// Package git 封装 gitdoc-cli 用到的 git 操作
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// Dir 返回当前仓库的 .git 目录
+func Dir() (string, error) {
+	output, err := utils.ExecCmd("git rev-parse --git-dir")
+	if err != nil {
+		return "", fmt.Errorf("获取 .git 目录失败，请确认当前目录是 git 仓库: %v", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// TopLevel 返回当前仓库工作区的根目录
+func TopLevel() (string, error) {
+	output, err := utils.ExecCmd("git rev-parse --show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("获取仓库根目录失败，请确认当前目录是 git 仓库: %v", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Prefix 返回当前目录相对仓库根目录的路径，在根目录时为空，否则以 / 结尾
+func Prefix() (string, error) {
+	output, err := utils.ExecCmd("git rev-parse --show-prefix")
+	if err != nil {
+		return "", fmt.Errorf("获取仓库路径失败，请确认当前目录是 git 仓库: %v", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// User 返回 git 配置中的用户名与邮箱
+func User() (name, email string) {
+	output, _ := utils.ExecCmd("git config --get user.name")
+	name = strings.TrimSpace(output)
+	output, _ = utils.ExecCmd("git config --get user.email")
+	email = strings.TrimSpace(output)
+	return name, email
+}
+
+// HooksDir 返回当前仓库的 hooks 目录，会遵循 core.hooksPath 配置
+func HooksDir() (string, error) {
+	output, err := utils.ExecCmd("git rev-parse --git-path hooks")
+	if err != nil {
+		return "", fmt.Errorf("获取 hooks 目录失败，请确认当前目录是 git 仓库: %v", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// StagedFiles 返回暂存区中新增、修改或重命名的文件
+func StagedFiles() ([]string, error) {
+	output, err := utils.ExecCmd("git diff --cached --name-only --diff-filter=ACMR -z")
+	if err != nil {
+		return nil, fmt.Errorf("获取暂存区文件失败: %v", err)
+	}
+	return splitNul(output), nil
+}
+
+// ChangedFiles 返回 from 到 to 之间新增、修改或重命名的文件；from 为空时（如新分支）与各个远端分支的共同祖先比较，
+// 没有远端分支时返回 to 中的全部文件
+func ChangedFiles(from, to string) ([]string, error) {
+	if from == "" {
+		from = RemoteBase(to)
+	}
+	cmd := fmt.Sprintf("git diff --name-only --diff-filter=ACMR -z %s %s", utils.ShellQuote(from), utils.ShellQuote(to))
+	if from == "" {
+		cmd = "git ls-tree -r --name-only -z " + utils.ShellQuote(to)
+	}
+	output, err := utils.ExecCmd(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("获取变更文件失败: %v", err)
+	}
+	return splitNul(output), nil
+}
+
+// StagedDeleted 返回暂存区中删除的文件
+func StagedDeleted() ([]string, error) {
+	output, err := utils.ExecCmd("git diff --cached --name-only --diff-filter=D -z")
+	if err != nil {
+		return nil, fmt.Errorf("获取暂存区文件失败: %v", err)
+	}
+	return splitNul(output), nil
+}
+
+// DeletedFiles 返回 from 到 to 之间删除的文件；from 为空时与各个远端分支的共同祖先比较，没有远端分支时返回空
+func DeletedFiles(from, to string) ([]string, error) {
+	if from == "" {
+		from = RemoteBase(to)
+	}
+	if from == "" {
+		return nil, nil
+	}
+	cmd := fmt.Sprintf("git diff --name-only --diff-filter=D -z %s %s", utils.ShellQuote(from), utils.ShellQuote(to))
+	output, err := utils.ExecCmd(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("获取变更文件失败: %v", err)
+	}
+	return splitNul(output), nil
+}
+
+// WorktreeChanges 返回工作区与暂存区中有变更的文件，包括未跟踪的文件，重命名的文件返回新路径；
+// 路径相对于当前目录
+func WorktreeChanges() ([]string, error) {
+	prefix, err := Prefix()
+	if err != nil {
+		return nil, err
+	}
+	output, err := utils.ExecCmd("git status --porcelain -z --untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("获取变更文件失败: %v", err)
+	}
+	var files []string
+	entries := strings.Split(output, "\x00")
+	for n := 0; n < len(entries); n++ {
+		entry := entries[n]
+		if len(entry) < 4 {
+			continue
+		}
+		if rel, err := filepath.Rel(filepath.FromSlash(prefix), filepath.FromSlash(entry[3:])); err == nil {
+			files = append(files, rel)
+		}
+		// 重命名与复制的下一项是原路径
+		if entry[0] == 'R' || entry[0] == 'C' {
+			n++
+		}
+	}
+	return files, nil
+}
+
+// Upstream 返回当前分支的上游提交，没有设置上游时返回空字符串
+func Upstream() string {
+	output, err := utils.ExecCmd("git rev-parse --verify -q @{u}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// Branch 返回当前分支名，处于分离头指针状态时返回空字符串
+func Branch() string {
+	output, err := utils.ExecCmd("git symbolic-ref --short -q HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// UpstreamName 返回当前分支的上游分支名，如 origin/main，没有设置上游时返回空字符串
+func UpstreamName() string {
+	output, err := utils.ExecCmd("git rev-parse --abbrev-ref -q @{u}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// RemoteBase 返回 rev 与各个远端分支最近的共同祖先，即 rev 中已推送到远端的最新提交；没有远端分支或没有共同祖先时返回空字符串
+func RemoteBase(rev string) string {
+	output, err := utils.ExecCmd("git for-each-ref --format='%(objectname)' refs/remotes")
+	refs := strings.Fields(output)
+	if err != nil || len(refs) == 0 {
+		return ""
+	}
+	output, err = utils.ExecCmd("git merge-base " + utils.ShellQuote(rev) + " " + utils.ShellQuoteAll(refs))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// Attr 返回 .gitattributes 中对路径 p 实际生效的属性 name 的值，如 filter；未设置时返回 unspecified
+func Attr(p, name string) (string, error) {
+	output, err := utils.ExecCmd("git check-attr " + utils.ShellQuote(name) + " -- " + utils.ShellQuote(p))
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 的属性 %s 失败: %v", p, name, err)
+	}
+	// 格式: <path>: <attribute>: <value>
+	fields := strings.Split(strings.TrimSpace(output), ": ")
+	return fields[len(fields)-1], nil
+}
+
+// Log 返回 from 到 to 之间的提交，每行为简短的提交 ID 与标题，from 为空时返回 to 的全部提交
+func Log(from, to string) ([]string, error) {
+	rev := utils.ShellQuote(to)
+	if from != "" {
+		rev = utils.ShellQuote(from + ".." + to)
+	}
+	output, err := utils.ExecCmd("git log --oneline --no-decorate " + rev)
+	if err != nil {
+		return nil, fmt.Errorf("获取提交记录失败: %v", err)
+	}
+	var commits []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// IsAncestor 提交 rev 是否是 HEAD 或其祖先
+func IsAncestor(rev string) bool {
+	_, err := utils.ExecCmd("git merge-base --is-ancestor " + utils.ShellQuote(rev) + " HEAD")
+	return err == nil
+}
+
+// Show 返回提交 rev 中文件 p 的内容
+func Show(rev, p string) ([]byte, error) {
+	// 文件可能是二进制文档，只读取标准输出
+	output, err := exec.Command("git", "show", rev+":"+p).Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s:%s 失败: %v", rev, p, err)
+	}
+	return output, nil
+}
+
+// HasObject 本地仓库中是否有提交 rev，如推送前远端分支已被他人更新、本地尚未 fetch 时没有
+func HasObject(rev string) bool {
+	_, err := utils.ExecCmd("git cat-file -e " + utils.ShellQuote(rev+"^{commit}"))
+	return err == nil
+}
+
+// Exists 文件 p 是否存在于提交 rev 中
+func Exists(rev, p string) bool {
+	_, err := utils.ExecCmd("git cat-file -e " + utils.ShellQuote(rev+":"+p))
+	return err == nil
+}
+
+// splitNul 拆分以 \0 分隔的输出
+func splitNul(output string) []string {
+	var files []string
+	for _, f := range strings.Split(output, "\x00") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// Add 将文件加入暂存区，paths 为空时加入全部变更
+func Add(paths ...string) error {
+	cmd := "git add --all"
+	if len(paths) > 0 {
+		cmd = "git add --all -- " + utils.ShellQuoteAll(paths)
+	}
+	if _, err := utils.ExecCmd(cmd); err != nil {
+		return fmt.Errorf("git add 失败: %v", err)
+	}
+	return nil
+}
+
+// Tracked 文件 p 是否已被 git 跟踪
+func Tracked(p string) bool {
+	_, err := utils.ExecCmd("git ls-files --error-unmatch -- " + utils.ShellQuote(p))
+	return err == nil
+}
+
+// Remove 以 git rm 删除已跟踪的文件
+func Remove(paths ...string) error {
+	if _, err := utils.ExecCmd("git rm -q -- " + utils.ShellQuoteAll(paths)); err != nil {
+		return fmt.Errorf("git rm 失败: %v", err)
+	}
+	return nil
+}
+
+// Move 以 git mv 移动已跟踪的文件
+func Move(from, to string) error {
+	if _, err := utils.ExecCmd("git mv -- " + utils.ShellQuoteAll([]string{from, to})); err != nil {
+		return fmt.Errorf("git mv %s 失败: %v", from, err)
+	}
+	return nil
+}
+
+// Commit 以 msg 作为提交信息执行 git commit，指定 paths 时只提交这些文件
+func Commit(msg string, paths ...string) error {
+	f, err := os.CreateTemp("", "gitdoc-commit-*.txt")
+	if err != nil {
+		return fmt.Errorf("创建提交信息文件失败: %v", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(msg)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("写入提交信息失败: %v", err)
+	}
+	// 标记由 gitdoc-cli 发起，hooks 不会重复转换与检查
+	cmd := fmt.Sprintf("%s=1 git commit -F %s", constant.EnvInternal, utils.ShellQuote(f.Name()))
+	if len(paths) > 0 {
+		cmd += " -- " + utils.ShellQuoteAll(paths)
+	}
+	if _, err := utils.ExecCmd(cmd); err != nil {
+		return fmt.Errorf("git commit 失败: %v", err)
+	}
+	return nil
+}
+
+// HasStaged 暂存区中是否有变更，指定 paths 时只检查这些文件
+func HasStaged(paths ...string) bool {
+	cmd := "git diff --cached --quiet"
+	if len(paths) > 0 {
+		cmd += " -- " + utils.ShellQuoteAll(paths)
+	}
+	_, err := utils.ExecCmd(cmd)
+	return err != nil
+}
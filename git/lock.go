@@ -0,0 +1,83 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrLocked 仓库正在被另一个 gitdoc-cli 进程操作
+var ErrLocked = errors.New("仓库正在被另一个 gitdoc-cli 进程操作")
+
+// lockFile 仓库锁文件，位于 .git 目录下
+const lockFile = "gitdoc.lock"
+
+// Lock 仓库锁，用于避免 watch 与手动执行的命令同时转换和提交
+type Lock struct {
+	path string
+}
+
+// AcquireLock 获取仓库锁，已被占用时返回 ErrLocked；持有锁的进程已退出时会自动清理
+func AcquireLock() (*Lock, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	p := filepath.Join(dir, lockFile)
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(p)
+				return nil, fmt.Errorf("写入仓库锁 %s 失败: %v", p, err)
+			}
+			return &Lock{path: p}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("创建仓库锁 %s 失败: %v", p, err)
+		}
+		pid, alive := holder(p)
+		if alive {
+			return nil, fmt.Errorf("%w (pid %d)，如确认没有其他进程可以删除 %s", ErrLocked, pid, p)
+		}
+		_ = os.Remove(p)
+	}
+	return nil, fmt.Errorf("%w，如确认没有其他进程可以删除 %s", ErrLocked, p)
+}
+
+// Release 释放仓库锁
+func (l *Lock) Release() {
+	if l == nil {
+		return
+	}
+	_ = os.Remove(l.path)
+}
+
+// holder 返回持有锁的进程 pid 及其是否仍在运行
+func holder(p string) (int, bool) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return 0, !os.IsNotExist(err)
+	}
+	// 锁文件刚创建尚未写入 pid 时，认为持有者仍在运行
+	if strings.TrimSpace(string(content)) == "" {
+		return 0, true
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, false
+	}
+	return pid, process.Signal(syscall.Signal(0)) == nil
+}
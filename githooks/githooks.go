@@ -0,0 +1,167 @@
+// Package githooks 安装和卸载调用 gitdoc-cli 的 git hooks
+package githooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/git"
+)
+
+// Names gitdoc-cli 会安装的 hooks
+var Names = []string{"pre-commit", "commit-msg", "pre-push"}
+
+const (
+	// marker 标记 hook 由 gitdoc-cli 生成
+	marker = "# gitdoc-cli hook"
+	// chainedSuffix 安装前已存在的 hook 会被重命名为 <hook>.gitdoc-chained，并在 gitdoc-cli 之前执行
+	chainedSuffix = ".gitdoc-chained"
+)
+
+// State hook 的安装状态
+type State int
+
+const (
+	// NotInstalled 未安装
+	NotInstalled State = iota
+	// Installed 已安装
+	Installed
+	// Foreign 存在其他工具生成的 hook，安装时会被串联
+	Foreign
+)
+
+// Status hook 的状态
+type Status struct {
+	// Name hook 名称
+	Name string
+	// State 安装状态
+	State State
+	// Chained 是否串联了安装前已存在的 hook
+	Chained bool
+	// Binary hook 调用的 gitdoc-cli 路径
+	Binary string
+}
+
+// script 生成 hook 脚本，优先使用安装时的 gitdoc-cli 路径，找不到时使用 PATH 中的 gitdoc-cli
+func script(name, binary string) string {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString(marker + ": 由 gitdoc-cli hooks install 生成，请勿手动修改\n")
+	fmt.Fprintf(&sb, "GITDOC_CLI='%s'\n", strings.ReplaceAll(binary, "'", `'\''`))
+	sb.WriteString("[ -x \"$GITDOC_CLI\" ] || GITDOC_CLI=gitdoc-cli\n")
+	fmt.Fprintf(&sb, "CHAINED=\"$(dirname \"$0\")/%s%s\"\n", name, chainedSuffix)
+	if name == "pre-push" {
+		// pre-push 从标准输入读取推送的分支，需要同时提供给串联的 hook
+		sb.WriteString("INPUT=\"$(cat)\"\n")
+		sb.WriteString("if [ -x \"$CHAINED\" ]; then\n")
+		sb.WriteString("  printf '%s\\n' \"$INPUT\" | \"$CHAINED\" \"$@\" || exit $?\n")
+		sb.WriteString("fi\n")
+		fmt.Fprintf(&sb, "printf '%%s\\n' \"$INPUT\" | \"$GITDOC_CLI\" hooks run %s \"$@\"\n", name)
+		return sb.String()
+	}
+	sb.WriteString("if [ -x \"$CHAINED\" ]; then\n")
+	sb.WriteString("  \"$CHAINED\" \"$@\" || exit $?\n")
+	sb.WriteString("fi\n")
+	fmt.Fprintf(&sb, "exec \"$GITDOC_CLI\" hooks run %s \"$@\"\n", name)
+	return sb.String()
+}
+
+// Install 安装全部 hooks，已存在的其他 hook 会被串联而不是覆盖
+func Install(binary string) ([]Status, error) {
+	dir, err := git.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建 hooks 目录 %s 失败: %v", dir, err)
+	}
+	for _, name := range Names {
+		p := filepath.Join(dir, name)
+		if state(p) == Foreign {
+			chained := p + chainedSuffix
+			if _, err := os.Stat(chained); err == nil {
+				return nil, fmt.Errorf("%s 与 %s 同时存在，请手动处理后重试", p, chained)
+			}
+			if err := os.Rename(p, chained); err != nil {
+				return nil, fmt.Errorf("串联已存在的 hook %s 失败: %v", p, err)
+			}
+		}
+		if err := os.WriteFile(p, []byte(script(name, binary)), 0755); err != nil {
+			return nil, fmt.Errorf("写入 hook %s 失败: %v", p, err)
+		}
+	}
+	return List()
+}
+
+// Uninstall 卸载全部 hooks，并恢复被串联的 hook
+func Uninstall() ([]Status, error) {
+	dir, err := git.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range Names {
+		p := filepath.Join(dir, name)
+		if state(p) != Installed {
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			return nil, fmt.Errorf("删除 hook %s 失败: %v", p, err)
+		}
+		chained := p + chainedSuffix
+		if _, err := os.Stat(chained); err == nil {
+			if err := os.Rename(chained, p); err != nil {
+				return nil, fmt.Errorf("恢复 hook %s 失败: %v", p, err)
+			}
+		}
+	}
+	return List()
+}
+
+// List 返回全部 hooks 的状态
+func List() ([]Status, error) {
+	dir, err := git.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(Names))
+	for _, name := range Names {
+		p := filepath.Join(dir, name)
+		s := Status{Name: name, State: state(p)}
+		if s.State == Installed {
+			s.Binary = binary(p)
+			if _, err := os.Stat(p + chainedSuffix); err == nil {
+				s.Chained = true
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// state 返回 hook 文件的安装状态
+func state(p string) State {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return NotInstalled
+	}
+	if strings.Contains(string(content), marker) {
+		return Installed
+	}
+	return Foreign
+}
+
+// binary 返回 hook 中记录的 gitdoc-cli 路径
+func binary(p string) string {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "GITDOC_CLI='") {
+			return strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(line, "GITDOC_CLI='"), "'"), `'\''`, "'")
+		}
+	}
+	return ""
+}
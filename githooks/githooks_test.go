@@ -0,0 +1,76 @@
+package githooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallChained(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, exec.Command("git", "init", "-q", dir).Run())
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	// 记录调用顺序、参数与标准输入的 hook 与 gitdoc-cli
+	calls := filepath.Join(dir, "calls.log")
+	record := func(name string) string {
+		return "#!/bin/sh\necho \"" + name + " $* $(cat)\" >> '" + calls + "'\n"
+	}
+	hooks := filepath.Join(dir, ".git", "hooks")
+	original := record("original")
+	assert.Nil(t, os.WriteFile(filepath.Join(hooks, "pre-push"), []byte(original), 0755))
+	binary := filepath.Join(dir, "gitdoc-cli")
+	assert.Nil(t, os.WriteFile(binary, []byte(record("gitdoc-cli")), 0755))
+
+	statuses, err := Install(binary)
+	assert.Nil(t, err)
+	assert.Equal(t, []Status{
+		{Name: "pre-commit", State: Installed, Binary: binary},
+		{Name: "commit-msg", State: Installed, Binary: binary},
+		{Name: "pre-push", State: Installed, Chained: true, Binary: binary},
+	}, statuses)
+	// 重复安装不会把 gitdoc-cli 的 hook 当作已存在的 hook 串联
+	_, err = Install(binary)
+	assert.Nil(t, err)
+
+	// 串联的 hook 先执行，两者都能读到推送信息
+	cmd := exec.Command(filepath.Join(hooks, "pre-push"), "origin", "url")
+	cmd.Stdin = strings.NewReader("refs/heads/main 1 refs/heads/main 2\n")
+	output, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(output))
+	content, err := os.ReadFile(calls)
+	assert.Nil(t, err)
+	assert.Equal(t, "original origin url refs/heads/main 1 refs/heads/main 2\n"+
+		"gitdoc-cli hooks run pre-push origin url refs/heads/main 1 refs/heads/main 2\n", string(content))
+
+	// 串联的 hook 失败时不再执行 gitdoc-cli
+	assert.Nil(t, os.Remove(calls))
+	assert.Nil(t, os.WriteFile(filepath.Join(hooks, "pre-push"+chainedSuffix), []byte("#!/bin/sh\nexit 3\n"), 0755))
+	cmd = exec.Command(filepath.Join(hooks, "pre-push"), "origin", "url")
+	cmd.Stdin = strings.NewReader("")
+	err = cmd.Run()
+	assert.NotNil(t, err)
+	_, err = os.Stat(calls)
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, os.WriteFile(filepath.Join(hooks, "pre-push"+chainedSuffix), []byte(original), 0755))
+
+	// 卸载后恢复原有的 hook
+	statuses, err = Uninstall()
+	assert.Nil(t, err)
+	assert.Equal(t, []Status{
+		{Name: "pre-commit", State: NotInstalled},
+		{Name: "commit-msg", State: NotInstalled},
+		{Name: "pre-push", State: Foreign},
+	}, statuses)
+	content, err = os.ReadFile(filepath.Join(hooks, "pre-push"))
+	assert.Nil(t, err)
+	assert.Equal(t, original, string(content))
+	_, err = os.Stat(filepath.Join(hooks, "pre-push"+chainedSuffix))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,144 @@
+// Package lfs 封装 gitdoc-cli 用到的 Git LFS 操作
+package lfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// Documents 使用 LFS 存储的文档
+var Documents = []string{"*.docx", "*.pptx", "*.xlsx"}
+
+// Patterns 返回使用 LFS 存储的路径模式，包括文档与转换时导出的媒体文件
+func Patterns() []string {
+	return append(append([]string{}, Documents...), convert.MediaPatterns()...)
+}
+
+// Version 返回 git lfs 的版本，未安装时返回错误
+func Version() (string, error) {
+	output, err := utils.ExecCmd("git lfs version")
+	if err != nil {
+		return "", fmt.Errorf("未检测到 Git LFS，请先安装: https://git-lfs.com")
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Installed 是否已安装 git lfs
+func Installed() bool {
+	_, err := Version()
+	return err == nil
+}
+
+// Install 配置 LFS 的 filter，在仓库中执行时还会安装 LFS 的 git hooks
+func Install() error {
+	if _, err := utils.ExecCmd("git lfs install"); err != nil {
+		return fmt.Errorf("git lfs install 失败: %v", err)
+	}
+	return nil
+}
+
+// Tracked 返回仓库根目录 .gitattributes 中使用 LFS 存储的路径模式
+func Tracked(root string) ([]string, error) {
+	filters, err := Filters(root)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	for _, f := range filters {
+		if f.Filter == "lfs" {
+			patterns = append(patterns, f.Pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// Filter .gitattributes 中为路径模式设置的 filter
+type Filter struct {
+	Pattern string
+	Filter  string
+}
+
+// Filters 按出现顺序返回仓库根目录 .gitattributes 中设置了 filter 的路径模式，同一模式出现多次时以最后一次为准
+func Filters(root string) ([]Filter, error) {
+	content, err := os.ReadFile(filepath.Join(root, ".gitattributes"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 .gitattributes 失败: %v", err)
+	}
+	var filters []Filter
+	index := make(map[string]int)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			name, ok := strings.CutPrefix(attr, "filter=")
+			if !ok {
+				continue
+			}
+			if n, ok := index[fields[0]]; ok {
+				filters[n].Filter = name
+				continue
+			}
+			index[fields[0]] = len(filters)
+			filters = append(filters, Filter{Pattern: fields[0], Filter: name})
+		}
+	}
+	return filters, nil
+}
+
+// Track 在 .gitattributes 中使用 LFS 存储 patterns，返回新增的路径模式；已跟踪的不会重复添加，
+// 已设置其他 filter（如 gitdoc）的路径模式会跳过并在 skipped 中返回，避免后添加的 LFS 规则使其失效
+func Track(root string, patterns []string) (added []string, skipped []Filter, err error) {
+	filters, err := Filters(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	bound := make(map[string]string, len(filters))
+	for _, f := range filters {
+		bound[f.Pattern] = f.Filter
+	}
+	for _, pattern := range patterns {
+		switch bound[pattern] {
+		case "lfs":
+			continue
+		case "":
+		default:
+			skipped = append(skipped, Filter{Pattern: pattern, Filter: bound[pattern]})
+			continue
+		}
+		cmd := fmt.Sprintf("cd %s && git lfs track %s", utils.ShellQuote(root), utils.ShellQuote(pattern))
+		if _, err := utils.ExecCmd(cmd); err != nil {
+			return added, skipped, fmt.Errorf("git lfs track %s 失败: %v", pattern, err)
+		}
+		added = append(added, pattern)
+	}
+	return added, skipped, nil
+}
+
+// Required 仓库是否使用了 LFS
+func Required(root string) bool {
+	tracked, err := Tracked(root)
+	return err == nil && len(tracked) > 0
+}
+
+// Migrate 将历史提交中匹配 patterns 的文件改为 LFS 存储，all 为 true 时改写全部分支与标签，否则只改写当前分支；
+// 会改写提交历史，已推送的分支需要强制推送
+func Migrate(patterns []string, all bool) error {
+	cmd := "git lfs migrate import --include=" + utils.ShellQuote(strings.Join(patterns, ","))
+	if all {
+		cmd += " --everything"
+	}
+	if _, err := utils.ExecCmd(cmd); err != nil {
+		return fmt.Errorf("git lfs migrate 失败: %v", err)
+	}
+	return nil
+}
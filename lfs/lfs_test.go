@@ -0,0 +1,39 @@
+package lfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracked(t *testing.T) {
+	root := t.TempDir()
+	tracked, err := Tracked(root)
+	assert.Nil(t, err)
+	assert.Empty(t, tracked)
+	assert.False(t, Required(root))
+
+	content := "# 文档\n*.docx filter=lfs diff=lfs merge=lfs -text\n*.md text\n" +
+		"media/** filter=lfs diff=lfs merge=lfs -text\n#*.pptx filter=lfs\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(root, ".gitattributes"), []byte(content), 0644))
+	tracked, err = Tracked(root)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"*.docx", "media/**"}, tracked)
+	assert.True(t, Required(root))
+}
+
+func TestTrackSkipsOtherFilters(t *testing.T) {
+	root := t.TempDir()
+	content := "*.docx filter=gitdoc\n*.pptx filter=lfs diff=lfs merge=lfs -text\n*.xlsx filter=gitdoc\n*.xlsx filter=lfs\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(root, ".gitattributes"), []byte(content), 0644))
+	filters, err := Filters(root)
+	assert.Nil(t, err)
+	assert.Equal(t, []Filter{{"*.docx", "gitdoc"}, {"*.pptx", "lfs"}, {"*.xlsx", "lfs"}}, filters)
+
+	added, skipped, err := Track(root, Documents)
+	assert.Nil(t, err)
+	assert.Empty(t, added)
+	assert.Equal(t, []Filter{{"*.docx", "gitdoc"}}, skipped)
+}
@@ -0,0 +1,189 @@
+package locks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"gopkg.in/yaml.v3"
+)
+
+// fileBackend 将文档锁记录在 .gitdoc/locks.yml 中，随提交推送给其他人
+type fileBackend struct {
+}
+
+// locksFile .gitdoc/locks.yml 的内容
+type locksFile struct {
+	Locks []Lock `yaml:"locks"`
+}
+
+// parseLocks 解析 .gitdoc/locks.yml 的内容
+func parseLocks(content []byte) ([]Lock, error) {
+	var f locksFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", constant.LocksFile, err)
+	}
+	return f.Locks, nil
+}
+
+// read 读取工作区中记录的文档锁 local，以及上游分支有本地尚未合并的提交时上游记录的锁 upstream；
+// 上游的锁只用于检查冲突，使其他人刚推送的锁也能生效，不会写入本地的 .gitdoc/locks.yml
+func (b fileBackend) read() (local, upstream []Lock, err error) {
+	root, err := git.TopLevel()
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(root, constant.LocksFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("读取 %s 失败: %v", constant.LocksFile, err)
+	}
+	if err == nil {
+		if local, err = parseLocks(content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	branch := git.Upstream()
+	if branch == "" || git.IsAncestor(branch) || !git.Exists(branch, constant.LocksFile) {
+		return local, nil, nil
+	}
+	content, err = git.Show(branch, constant.LocksFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upstream, err = parseLocks(content); err != nil {
+		return nil, nil, err
+	}
+	return local, upstream, nil
+}
+
+// merge 合并本地与上游记录的锁，同一文档以本地记录为准
+func merge(local, upstream []Lock) []Lock {
+	locks := append([]Lock{}, local...)
+	seen := make(map[string]bool, len(local))
+	for _, l := range local {
+		seen[l.Path] = true
+	}
+	for _, l := range upstream {
+		if !seen[l.Path] {
+			locks = append(locks, l)
+		}
+	}
+	return locks
+}
+
+// write 写入工作区中的文档锁并单独提交 .gitdoc/locks.yml，不会带上暂存区中的其他变更
+func (b fileBackend) write(locks []Lock, msg string) error {
+	root, err := git.TopLevel()
+	if err != nil {
+		return err
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Path < locks[j].Path
+	})
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(locksFile{Locks: locks}); err != nil {
+		return fmt.Errorf("生成 %s 失败: %v", constant.LocksFile, err)
+	}
+	p := filepath.Join(root, constant.LocksFile)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(p, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", p, err)
+	}
+	if err := git.Add(p); err != nil {
+		return err
+	}
+	if err := git.Commit(msg, p); err != nil {
+		return err
+	}
+	log.Info("已提交 %s，推送后其他人可见", constant.LocksFile)
+	return nil
+}
+
+func (b fileBackend) List() ([]Lock, error) {
+	local, upstream, err := b.read()
+	if err != nil {
+		return nil, err
+	}
+	return markOurs(merge(local, upstream)), nil
+}
+
+// markOurs 标记当前用户持有的锁
+func markOurs(locks []Lock) []Lock {
+	name, email := git.User()
+	for i := range locks {
+		locks[i].Ours = owns(locks[i], name, email)
+	}
+	return locks
+}
+
+func (b fileBackend) Lock(p string) (Lock, error) {
+	local, upstream, err := b.read()
+	if err != nil {
+		return Lock{}, err
+	}
+	for _, l := range markOurs(merge(local, upstream)) {
+		if l.Path != p {
+			continue
+		}
+		if l.Ours {
+			return l, nil
+		}
+		return Lock{}, fmt.Errorf("%s 已被 %s 锁定", p, l.Describe())
+	}
+	name, email := git.User()
+	if name == "" && email == "" {
+		return Lock{}, fmt.Errorf("未设置 git 用户信息，可以执行 gitdoc-cli init 设置")
+	}
+	lock := Lock{Path: p, Owner: name, Email: email, Time: time.Now().UTC().Truncate(time.Second), Ours: true}
+	if err := b.write(append(local, lock), "lock: "+p); err != nil {
+		return Lock{}, err
+	}
+	return lock, nil
+}
+
+func (b fileBackend) Unlock(p string, force bool) error {
+	local, upstream, err := b.read()
+	if err != nil {
+		return err
+	}
+	remaining := make([]Lock, 0, len(local))
+	found := false
+	for _, l := range markOurs(local) {
+		if l.Path != p {
+			remaining = append(remaining, l)
+			continue
+		}
+		if !l.Ours && !force {
+			return fmt.Errorf("%s 已被 %s 锁定，可以使用 --force 强制解锁", p, l.Describe())
+		}
+		found = true
+	}
+	if !found {
+		for _, l := range upstream {
+			if l.Path == p {
+				return fmt.Errorf("%s 的锁记录在上游分支 %s 中，请先合并上游分支再解锁", p, git.UpstreamName())
+			}
+		}
+		return fmt.Errorf("%s 未被锁定", p)
+	}
+	return b.write(remaining, "unlock: "+p)
+}
+
+// owns 文档锁是否由 name/email 对应的用户持有，记录了邮箱时按邮箱判断
+func owns(l Lock, name, email string) bool {
+	if l.Email != "" && email != "" {
+		return l.Email == email
+	}
+	return l.Owner == name
+}
@@ -0,0 +1,68 @@
+package locks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/constant"
+)
+
+// gitIn 在 dir 中执行 git 命令
+func gitIn(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(output))
+}
+
+func TestFileBackendUpstream(t *testing.T) {
+	dir := t.TempDir()
+	remote, ours, theirs := filepath.Join(dir, "remote.git"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")
+	gitIn(t, dir, "init", "-q", "--bare", remote)
+	for _, clone := range []string{ours, theirs} {
+		gitIn(t, dir, "clone", "-q", remote, clone)
+		gitIn(t, clone, "config", "user.name", filepath.Base(clone))
+		gitIn(t, clone, "config", "user.email", filepath.Base(clone)+"@example.com")
+	}
+	gitIn(t, ours, "commit", "-q", "--allow-empty", "-m", "init")
+	gitIn(t, ours, "push", "-q", "origin", "HEAD:main")
+	gitIn(t, ours, "branch", "-q", "-u", "origin/main")
+	gitIn(t, theirs, "fetch", "-q")
+	gitIn(t, theirs, "checkout", "-q", "-b", "main", "origin/main")
+
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+	b := fileBackend{}
+	assert.Nil(t, os.Chdir(theirs))
+	_, err := b.Lock("a.docx")
+	assert.Nil(t, err)
+	gitIn(t, theirs, "push", "-q", "origin", "main")
+
+	// 上游的锁用于检查冲突，但不会写入本地的 locks.yml
+	assert.Nil(t, os.Chdir(ours))
+	gitIn(t, ours, "fetch", "-q")
+	_, err = b.Lock("a.docx")
+	assert.NotNil(t, err)
+	_, err = b.Lock("b.docx")
+	assert.Nil(t, err)
+	local, err := parseLocksFile(filepath.Join(ours, constant.LocksFile))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(local))
+	assert.Equal(t, "b.docx", local[0].Path)
+	list, err := b.List()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.NotNil(t, b.Unlock("a.docx", true))
+}
+
+// parseLocksFile 读取并解析文件中的文档锁
+func parseLocksFile(p string) ([]Lock, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return parseLocks(content)
+}
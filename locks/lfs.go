@@ -0,0 +1,110 @@
+package locks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+)
+
+// lfsBackend 使用 Git LFS 的文件锁，锁保存在 LFS 服务端
+type lfsBackend struct {
+}
+
+// lfsLock git lfs locks --json 输出的锁
+type lfsLock struct {
+	ID    string `json:"id"`
+	Path  string `json:"path"`
+	Owner struct {
+		Name string `json:"name"`
+	} `json:"owner"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+func (l lfsLock) lock(ours bool) Lock {
+	return Lock{Path: l.Path, Owner: l.Owner.Name, Time: l.LockedAt, Ours: ours}
+}
+
+func (b lfsBackend) List() ([]Lock, error) {
+	// --verify 由服务端区分自己与其他人的锁
+	output, err := runLFS("locks", "--verify", "--json")
+	if err == nil {
+		var verified struct {
+			Ours   []lfsLock `json:"ours"`
+			Theirs []lfsLock `json:"theirs"`
+		}
+		if err := json.Unmarshal(output, &verified); err != nil {
+			return nil, fmt.Errorf("解析 git lfs locks 的输出失败: %v", err)
+		}
+		locks := make([]Lock, 0, len(verified.Ours)+len(verified.Theirs))
+		for _, l := range verified.Ours {
+			locks = append(locks, l.lock(true))
+		}
+		for _, l := range verified.Theirs {
+			locks = append(locks, l.lock(false))
+		}
+		return locks, nil
+	}
+
+	// 部分 LFS 服务端不支持 --verify，按 git 用户名判断
+	log.Debug("git lfs locks --verify 失败，按 git 用户名判断锁的持有者: %v", err)
+	output, err = runLFS("locks", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("获取 LFS 文件锁失败: %v", err)
+	}
+	var all []lfsLock
+	if err := json.Unmarshal(output, &all); err != nil {
+		return nil, fmt.Errorf("解析 git lfs locks 的输出失败: %v", err)
+	}
+	name, _ := git.User()
+	locks := make([]Lock, 0, len(all))
+	for _, l := range all {
+		locks = append(locks, l.lock(l.Owner.Name == name))
+	}
+	return locks, nil
+}
+
+func (b lfsBackend) Lock(p string) (Lock, error) {
+	output, err := runLFS("lock", "--json", p)
+	if err != nil {
+		return Lock{}, fmt.Errorf("锁定 %s 失败: %v", p, err)
+	}
+	var l lfsLock
+	if err := json.Unmarshal(output, &l); err != nil {
+		return Lock{}, fmt.Errorf("解析 git lfs lock 的输出失败: %v", err)
+	}
+	return l.lock(true), nil
+}
+
+func (b lfsBackend) Unlock(p string, force bool) error {
+	args := []string{"unlock"}
+	if force {
+		args = append(args, "--force")
+	}
+	if _, err := runLFS(append(args, p)...); err != nil {
+		return fmt.Errorf("解锁 %s 失败: %v", p, err)
+	}
+	return nil
+}
+
+// runLFS 在仓库根目录执行 git lfs：文档锁的路径相对于仓库根目录，而 git lfs 按当前目录解析路径；
+// 只读取标准输出，git lfs 输出到标准错误的警告不会混入 json
+func runLFS(args ...string) ([]byte, error) {
+	root, err := git.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("git", append([]string{"lfs"}, args...)...)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
@@ -0,0 +1,173 @@
+// Package locks 记录与检查文档锁，避免多人同时修改无法合并的文档
+package locks
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+)
+
+// 文档锁的存储方式
+const (
+	// BackendFile 记录在提交到仓库的 .gitdoc/locks.yml 中
+	BackendFile = "file"
+	// BackendLFS 使用 Git LFS 的文件锁
+	BackendLFS = "lfs"
+)
+
+// 提交或推送被其他人锁定的文档时的处理方式
+const (
+	// EnforceWarn 只输出警告
+	EnforceWarn = "warn"
+	// EnforceRefuse 终止提交或推送
+	EnforceRefuse = "refuse"
+)
+
+// Lock 一个文档锁
+type Lock struct {
+	// Path 文档在仓库中的路径
+	Path string `yaml:"path"`
+	// Owner 持有者的 git 用户名
+	Owner string `yaml:"owner"`
+	// Email 持有者的 git 邮箱
+	Email string `yaml:"email,omitempty"`
+	// Time 锁定时间
+	Time time.Time `yaml:"time"`
+	// Ours 是否由当前用户持有
+	Ours bool `yaml:"-"`
+}
+
+// Backend 文档锁的存储
+type Backend interface {
+	// Lock 锁定文档 p，已被其他人锁定时返回错误
+	Lock(p string) (Lock, error)
+	// Unlock 解锁文档 p，force 为 true 时可以解除其他人的锁
+	Unlock(p string, force bool) error
+	// List 返回全部文档锁
+	List() ([]Lock, error)
+}
+
+// New 按 lock.backend 配置返回文档锁的存储
+func New() Backend {
+	if viper.GetString(config.KeyLockBackend) == BackendLFS {
+		return lfsBackend{}
+	}
+	return fileBackend{}
+}
+
+// Normalize 将相对当前目录的路径转换为仓库中的路径
+func Normalize(p string) (string, error) {
+	prefix, err := git.Prefix()
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(p) {
+		root, err := git.TopLevel()
+		if err != nil {
+			return "", err
+		}
+		if p, err = filepath.Rel(root, p); err != nil {
+			return "", fmt.Errorf("%s 不在仓库中: %v", p, err)
+		}
+		prefix = ""
+	}
+	p = path.Clean(prefix + filepath.ToSlash(p))
+	if p == "." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("%s 不在仓库中", p)
+	}
+	return p, nil
+}
+
+// Describe 返回锁的持有者与锁定时间的说明
+func (l Lock) Describe() string {
+	owner := l.Owner
+	if l.Email != "" {
+		owner = fmt.Sprintf("%s <%s>", l.Owner, l.Email)
+	}
+	if l.Time.IsZero() {
+		return owner
+	}
+	return fmt.Sprintf("%s，锁定于 %s", owner, l.Time.Local().Format("2006-01-02 15:04"))
+}
+
+// Conflicts 返回 paths 中被其他人锁定的文档
+func Conflicts(locks []Lock, paths []string) []Lock {
+	held := make(map[string]Lock)
+	for _, l := range locks {
+		if !l.Ours {
+			held[l.Path] = l
+		}
+	}
+	var conflicts []Lock
+	for _, p := range paths {
+		if l, ok := held[filepath.ToSlash(p)]; ok {
+			conflicts = append(conflicts, l)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Path < conflicts[j].Path
+	})
+	return conflicts
+}
+
+// Verify 检查 paths 中是否有被其他人锁定的文档，按 lock.enforce 配置输出警告或返回错误
+func Verify(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	locks, err := New().List()
+	if err != nil {
+		return err
+	}
+	conflicts := Conflicts(locks, paths)
+	if len(conflicts) == 0 {
+		return nil
+	}
+	refuse := viper.GetString(config.KeyLockEnforce) != EnforceWarn
+	report := log.Warn
+	if refuse {
+		report = log.Error
+	}
+	report("%d 个文档已被其他人锁定:", len(conflicts))
+	for _, l := range conflicts {
+		report("  %s: %s", l.Path, l.Describe())
+	}
+	if !refuse {
+		return nil
+	}
+	return fmt.Errorf("不能修改其他人锁定的文档，请联系持有者解锁，或使用 --no-verify 跳过检查")
+}
+
+// VerifyStaged 检查暂存区中变更的文档是否被其他人锁定
+func VerifyStaged() error {
+	changed, err := git.StagedFiles()
+	if err != nil {
+		return err
+	}
+	deleted, err := git.StagedDeleted()
+	if err != nil {
+		return err
+	}
+	return Verify(append(changed, deleted...))
+}
+
+// VerifyRevision 检查 from 到 to 之间变更的文档是否被其他人锁定
+func VerifyRevision(from, to string) error {
+	changed, err := git.ChangedFiles(from, to)
+	if err != nil {
+		return err
+	}
+	deleted, err := git.DeletedFiles(from, to)
+	if err != nil {
+		return err
+	}
+	return Verify(append(changed, deleted...))
+}
@@ -0,0 +1,38 @@
+package locks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocks(t *testing.T) {
+	locks, err := parseLocks([]byte("locks:\n  - path: docs/合同.docx\n    owner: 张三\n    email: zs@example.com\n    time: 2024-01-02T03:04:05Z\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Lock{{
+		Path:  "docs/合同.docx",
+		Owner: "张三",
+		Email: "zs@example.com",
+		Time:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}, locks)
+
+	_, err = parseLocks([]byte("locks: ["))
+	assert.NotNil(t, err)
+}
+
+func TestOwns(t *testing.T) {
+	assert.True(t, owns(Lock{Owner: "张三", Email: "zs@example.com"}, "zhangsan", "zs@example.com"))
+	assert.False(t, owns(Lock{Owner: "张三", Email: "zs@example.com"}, "张三", "other@example.com"))
+	assert.True(t, owns(Lock{Owner: "张三"}, "张三", "zs@example.com"))
+}
+
+func TestConflicts(t *testing.T) {
+	all := []Lock{
+		{Path: "b.docx", Owner: "李四"},
+		{Path: "a.docx", Owner: "王五"},
+		{Path: "mine.docx", Owner: "张三", Ours: true},
+	}
+	conflicts := Conflicts(all, []string{"mine.docx", "b.docx", "c.docx", "a.docx"})
+	assert.Equal(t, []Lock{{Path: "a.docx", Owner: "王五"}, {Path: "b.docx", Owner: "李四"}}, conflicts)
+}
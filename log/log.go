@@ -45,11 +45,21 @@ func Normal(format string, a ...interface{}) {
 	DefaultStd.WithAddCallDepth(1).Normal(format, a...)
 }
 
+// Prompt 蓝色，打印等待用户输入的提示，--quiet 时也会输出
+func Prompt(format string, a ...interface{}) {
+	DefaultStd.WithAddCallDepth(1).Prompt(format, a...)
+}
+
 // Prefix 返回一个标准的带有前缀的Printer，高频接口单独独立一个函数
 func Prefix(prefix string) Printer {
 	return DefaultStd.WithPrefix(prefix)
 }
 
+// NewProgress 使用默认的Printer开始一个进度条，total 为任务总数，未知时传 0
+func NewProgress(title string, total int) *Progress {
+	return DefaultStd.NewProgress(title, total)
+}
+
 // Inline 返回一个标准的不换行打印的Printer，高频接口单独独立一个函数
 func Inline() Printer {
 	return DefaultStd.WithInline()
@@ -132,3 +132,24 @@ func (p *PrinterSuite) TestAlarm() {
 	err = Alarm("Error", ErrorLevel)
 	p.Suite.NotNil(err)
 }
+
+func (p *PrinterSuite) TestLevel() {
+	var lines []string
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(writerImp{}), "Write",
+		func(w writerImp, b []byte) (n int, err error) {
+			lines = append(lines, string(b))
+			return len(b), nil
+		},
+	)
+	defer patches.Reset()
+
+	printer := New().WithDisableColor().WithLevel(LevelWarn)
+	printer.Trace("trace")
+	printer.Debug("debug")
+	printer.Info("info")
+	printer.Warn("warn")
+	printer.Error("error")
+	printer.Normal("normal")
+	printer.Prompt("prompt")
+	p.Suite.Equal([]string{"warn\n", "error\n", "normal\n", "prompt\n"}, lines)
+}
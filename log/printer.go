@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/zhihanggg/gitdoc-cli/constant"
 )
@@ -20,6 +21,11 @@ type Option struct {
 	Prefix string
 	// EnableInline 不换行打印，可以用于交互输入
 	EnableInline bool
+	// Sinks 标准错误之外的输出目标
+	Sinks []Sink
+	// Level 标准错误输出的最低级别，低于该级别的 Debug、Info、Warn 与 Error 日志不会输出；
+	// Trace 仍由 EnableTrace 控制，Normal 与 Prompt 总是输出
+	Level Level
 }
 
 // Printer 颜色打印器
@@ -55,16 +61,13 @@ func (p writerImp) Write(b []byte) (n int, err error) {
 
 // New 新建一个Printer
 func New() *Printer {
-	disableColor := false
-	if runtime.GOOS == constant.OSWindows { // windows环境不支持日志输出带颜色，否则会产生乱码
-		disableColor = true
-	}
 	p := &Printer{
 		Option: Option{
-			DisableColor: disableColor,
+			DisableColor: !colorSupported(),
 			EnableTrace:  false,
 			Prefix:       "",
 			EnableInline: false,
+			Level:        LevelDebug,
 		},
 		callDepth: 3,
 	}
@@ -75,6 +78,14 @@ func New() *Printer {
 	return p
 }
 
+// colorSupported 标准错误是否可以彩色输出：windows环境输出颜色会产生乱码，设置了 NO_COLOR 环境变量或标准错误不是终端时也不输出颜色
+func colorSupported() bool {
+	if runtime.GOOS == constant.OSWindows || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stderr)
+}
+
 // NewErrorf 生成一个带有前缀的error
 func (p Printer) NewErrorf(format string, a ...interface{}) error {
 	p.setPrefix(&format)
@@ -116,15 +127,31 @@ func (p Printer) WithDisableColor() Printer {
 	return p
 }
 
+// WithLevel 返回标准错误输出最低级别为 level 的Printer
+func (p Printer) WithLevel(level Level) Printer {
+	p.Level = level
+	return p
+}
+
 // WithInline 返回用户输出不换行的信息的Printer
 func (p Printer) WithInline() Printer {
 	p.EnableInline = true
 	return p
 }
 
-// output 公共输出
-func (p Printer) output(callDepth int, color TypeColor, format string, a ...interface{}) {
+// output 公共输出，同时写入标准错误与各个输出目标；标准错误的输出串行进行，显示进度条时输出在进度条上方
+func (p Printer) output(callDepth int, level Level, color TypeColor, format string, a ...interface{}) {
+	if len(p.Sinks) > 0 {
+		p.outputSinks(callDepth, level, format, a...)
+	}
+	if level == LevelTrace && !p.EnableTrace || level > LevelTrace && level < LevelNormal && level < p.Level {
+		return
+	}
 	p.setPrefix(&format)
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	clearProgress()
+	defer drawProgress()
 	if p.EnableTrace {
 		if p.EnableInline {
 			_ = p.traceLoggerInline.Output(callDepth, p.Color(color, format, a...))
@@ -140,36 +167,56 @@ func (p Printer) output(callDepth int, color TypeColor, format string, a ...inte
 	_ = p.defaultLogger.Output(callDepth, p.Color(color, format, a...))
 }
 
+// outputSinks 将日志写入各个输出目标，不带颜色；写入失败时不影响标准错误的输出
+func (p Printer) outputSinks(callDepth int, level Level, format string, a ...interface{}) {
+	e := Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Prefix:  p.Prefix,
+		Caller:  caller(callDepth),
+		Message: fmt.Sprintf(format, a...),
+	}
+	for _, s := range p.Sinks {
+		if level == LevelTrace && !s.Trace && !p.EnableTrace {
+			continue
+		}
+		_ = s.write(e)
+	}
+}
+
 // Trace 用于打印开发阶段的信息
 func (p Printer) Trace(format string, a ...interface{}) {
-	if p.EnableTrace {
-		p.output(p.callDepth, None, format, a...)
-	}
+	p.output(p.callDepth, LevelTrace, None, format, a...)
 }
 
 // Debug 用于打印检查正常的信息
 func (p Printer) Debug(format string, a ...interface{}) {
-	p.output(p.callDepth, Green, format, a...)
+	p.output(p.callDepth, LevelDebug, Green, format, a...)
 }
 
 // Info 用于打印修改操作的信息
 func (p Printer) Info(format string, a ...interface{}) {
-	p.output(p.callDepth, Blue, format, a...)
+	p.output(p.callDepth, LevelInfo, Blue, format, a...)
 }
 
 // Warn 用于打印检查不正确的信息
 func (p Printer) Warn(format string, a ...interface{}) {
-	p.output(p.callDepth, Yellow, format, a...)
+	p.output(p.callDepth, LevelWarn, Yellow, format, a...)
 }
 
 // Error 用于打印错误信息
 func (p Printer) Error(format string, a ...interface{}) {
-	p.output(p.callDepth, Red, format, a...)
+	p.output(p.callDepth, LevelError, Red, format, a...)
 }
 
 // Normal 用于无颜色打印
 func (p Printer) Normal(format string, a ...interface{}) {
-	p.output(p.callDepth, None, format, a...)
+	p.output(p.callDepth, LevelNormal, None, format, a...)
+}
+
+// Prompt 用于打印等待用户输入的提示，与 Info 颜色相同，不受 Level 限制
+func (p Printer) Prompt(format string, a ...interface{}) {
+	p.output(p.callDepth, LevelNormal, Blue, format, a...)
 }
 
 // Color 颜色输出
@@ -0,0 +1,196 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outputMu 串行化标准错误的输出，并发打印的日志不会交错，也不会与进度条混在同一行
+var outputMu sync.Mutex
+
+// active 当前显示在终端最后一行的进度条，由 outputMu 保护
+var active *Progress
+
+// spinnerFrames 进度条前的转圈动画
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// progressWidth 进度条的宽度
+	progressWidth = 20
+	// progressTaskWidth 进度条后显示的任务名称的最大长度，超出时保留末尾
+	progressTaskWidth = 40
+	// progressInterval 进度条的刷新间隔
+	progressInterval = 100 * time.Millisecond
+)
+
+// Progress 进度条；标准错误是终端时在最后一行实时刷新，期间打印的日志会输出在进度条上方，
+// 否则每完成一个任务打印一行进度
+type Progress struct {
+	// printer 打印进度与任务日志的Printer
+	printer Printer
+	// title 进度条标题
+	title string
+	// total 任务总数，为 0 时只显示转圈动画与耗时
+	total int
+	// live 是否在终端上实时刷新
+	live bool
+	// started 开始时间
+	started time.Time
+	// stop 关闭后停止刷新
+	stop chan struct{}
+	// stopped 刷新停止后关闭
+	stopped chan struct{}
+
+	// 以下字段由 outputMu 保护
+	// done 已完成的任务数
+	done int
+	// current 正在执行的任务
+	current string
+	// frame 转圈动画的帧
+	frame int
+}
+
+// NewProgress 开始一个进度条，total 为任务总数，未知时传 0 显示转圈动画；使用完需要调用 Finish。
+// 进度条只输出到标准错误，不写入 Sinks；NO_COLOR 只关闭颜色，仍会实时刷新；期间执行的命令不能在终端上与用户交互
+func (p Printer) NewProgress(title string, total int) *Progress {
+	b := &Progress{
+		printer: p,
+		title:   title,
+		total:   total,
+		live:    p.Level <= LevelInfo && !p.EnableInline && liveSupported(),
+		started: time.Now(),
+	}
+	if !b.live {
+		return b
+	}
+	b.stop = make(chan struct{})
+	b.stopped = make(chan struct{})
+	outputMu.Lock()
+	active = b
+	b.draw()
+	outputMu.Unlock()
+	go b.refresh()
+	return b
+}
+
+// Start 标记任务 task 开始执行，返回以任务名称为前缀的Printer，用于打印该任务的日志
+func (b *Progress) Start(task string) Printer {
+	outputMu.Lock()
+	b.current = task
+	outputMu.Unlock()
+	return b.printer.WithPrefix(task)
+}
+
+// Done 标记任务 task 执行完成，非终端时打印一行进度
+func (b *Progress) Done(task string) {
+	outputMu.Lock()
+	b.done++
+	done := b.done
+	if b.current == task {
+		b.current = ""
+	}
+	outputMu.Unlock()
+	if !b.live && b.total > 0 {
+		b.printer.WithPrefix(b.title).Info("%d/%d %s", done, b.total, task)
+	}
+}
+
+// Finish 停止刷新并清除进度条
+func (b *Progress) Finish() {
+	if !b.live {
+		return
+	}
+	select {
+	case <-b.stop:
+		return
+	default:
+	}
+	close(b.stop)
+	<-b.stopped
+	outputMu.Lock()
+	clearProgress()
+	if active == b {
+		active = nil
+	}
+	outputMu.Unlock()
+}
+
+// refresh 定时刷新进度条，直到调用 Finish
+func (b *Progress) refresh() {
+	defer close(b.stopped)
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			outputMu.Lock()
+			b.frame++
+			if active == b {
+				b.draw()
+			}
+			outputMu.Unlock()
+		}
+	}
+}
+
+// draw 在终端最后一行重新绘制进度条，调用方需持有 outputMu
+func (b *Progress) draw() {
+	_, _ = os.Stderr.WriteString("\r\033[K" + b.line())
+}
+
+// line 返回进度条的内容，如 ⠋ 转换文档 [========            ] 2/5 a.docx 3s
+func (b *Progress) line() string {
+	parts := []string{b.printer.Color(Blue, "%s", spinnerFrames[b.frame%len(spinnerFrames)]), b.title}
+	if b.total > 0 {
+		filled := progressWidth * b.done / b.total
+		if filled > progressWidth {
+			filled = progressWidth
+		}
+		parts = append(parts, "["+strings.Repeat("=", filled)+strings.Repeat(" ", progressWidth-filled)+"]",
+			fmt.Sprintf("%d/%d", b.done, b.total))
+	}
+	if b.current != "" {
+		parts = append(parts, shorten(b.current, progressTaskWidth))
+	}
+	parts = append(parts, time.Since(b.started).Round(time.Second).String())
+	return strings.Join(parts, " ")
+}
+
+// clearProgress 清除终端最后一行的进度条，调用方需持有 outputMu
+func clearProgress() {
+	if active != nil {
+		_, _ = os.Stderr.WriteString("\r\033[K")
+	}
+}
+
+// drawProgress 日志输出后在最后一行重新绘制进度条，调用方需持有 outputMu
+func drawProgress() {
+	if active != nil {
+		active.draw()
+	}
+}
+
+// shorten 字符数超过 n 时只保留末尾部分，以 … 开头
+func shorten(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return "…" + string(r[len(r)-n+1:])
+}
+
+// liveSupported 标准错误是否可以实时刷新进度条，TERM=dumb 的终端不支持清除行的控制字符
+func liveSupported() bool {
+	return isTerminal(os.Stderr) && os.Getenv("TERM") != "dumb"
+}
+
+// isTerminal 文件是否是终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureStderr 记录写入标准错误的日志
+func captureStderr() (*[]string, *gomonkey.Patches) {
+	var lines []string
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(writerImp{}), "Write",
+		func(w writerImp, b []byte) (n int, err error) {
+			lines = append(lines, string(b))
+			return len(b), nil
+		},
+	)
+	return &lines, patches
+}
+
+func TestProgressPlain(t *testing.T) {
+	lines, patches := captureStderr()
+	defer patches.Reset()
+
+	bar := New().WithDisableColor().NewProgress("转换文档", 2)
+	assert.False(t, bar.live)
+	bar.Start("a.docx").Debug("正在转换")
+	bar.Done("a.docx")
+	bar.Start("b.docx").Error("转换失败")
+	bar.Done("b.docx")
+	bar.Finish()
+	assert.Equal(t, []string{"[a.docx]正在转换\n", "[转换文档]1/2 a.docx\n",
+		"[b.docx]转换失败\n", "[转换文档]2/2 b.docx\n"}, *lines)
+}
+
+func TestProgressLine(t *testing.T) {
+	bar := &Progress{printer: New().WithDisableColor(), title: "转换文档", total: 4, done: 1, current: "a.docx",
+		started: time.Now()}
+	assert.Equal(t, "⠋ 转换文档 [=====               ] 1/4 a.docx 0s", bar.line())
+
+	bar = &Progress{printer: New().WithDisableColor(), title: "git push", frame: 1, started: time.Now()}
+	assert.Equal(t, "⠙ git push 0s", bar.line())
+
+	assert.Equal(t, "…ocx", shorten("docs/a.docx", 4))
+}
+
+func TestConcurrentOutput(t *testing.T) {
+	lines, patches := captureStderr()
+	defer patches.Reset()
+
+	printer := New().WithDisableColor()
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func(task string) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				printer.WithPrefix(task).Info("第 %d 行", i)
+			}
+		}("task" + strconv.Itoa(n))
+	}
+	wg.Wait()
+	assert.Equal(t, 200, len(*lines))
+	for _, line := range *lines {
+		assert.True(t, strings.HasPrefix(line, "[task") && strings.HasSuffix(line, "行\n"), line)
+	}
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level 日志级别
+type Level int
+
+const (
+	// LevelTrace 调试信息
+	LevelTrace Level = iota
+	// LevelDebug 正常消息提示
+	LevelDebug
+	// LevelInfo 重要信息提示
+	LevelInfo
+	// LevelWarn 警示信息
+	LevelWarn
+	// LevelError 错误信息
+	LevelError
+	// LevelNormal 无颜色打印的信息，如列表、表格等命令的输出结果
+	LevelNormal
+)
+
+// levelNames 日志级别名称
+var levelNames = map[Level]string{
+	LevelTrace:  "trace",
+	LevelDebug:  "debug",
+	LevelInfo:   "info",
+	LevelWarn:   "warn",
+	LevelError:  "error",
+	LevelNormal: "normal",
+}
+
+// String 返回日志级别名称
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// Format 输出目标的日志格式
+type Format int
+
+const (
+	// FormatText 文本格式：时间 级别 调用位置: [前缀]信息
+	FormatText Format = iota
+	// FormatJSON 每行一个 json 对象，包含 time、level、prefix、caller 与 message
+	FormatJSON
+)
+
+// Sink 标准错误之外的日志输出目标，如日志文件
+type Sink struct {
+	// Writer 输出目标
+	Writer io.Writer
+	// Format 日志格式
+	Format Format
+	// Trace 为 true 时 Trace 日志也会输出到该目标，不受 Printer.EnableTrace 影响
+	Trace bool
+}
+
+// Entry 一条日志
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Prefix  string    `json:"prefix,omitempty"`
+	Caller  string    `json:"caller,omitempty"`
+	Message string    `json:"message"`
+}
+
+// MarshalJSON 日志级别以名称输出
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return json.Marshal(struct {
+		entry
+		Level string `json:"level"`
+	}{entry(e), e.Level.String()})
+}
+
+// sinkMu 保证多个 Printer 同时写入同一个输出目标时日志不会交错
+var sinkMu sync.Mutex
+
+// write 将日志按格式写入输出目标
+func (s Sink) write(e Entry) error {
+	var line []byte
+	switch s.Format {
+	case FormatJSON:
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		line = append(b, '\n')
+	default:
+		msg := e.Message
+		if e.Prefix != "" {
+			msg = "[" + e.Prefix + "]" + msg
+		}
+		line = []byte(fmt.Sprintf("%s %-6s %s: %s\n", e.Time.Format("2006-01-02 15:04:05.000"),
+			strings.ToUpper(e.Level.String()), e.Caller, msg))
+	}
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	_, err := s.Writer.Write(line)
+	return err
+}
+
+// caller 返回调用位置，格式为 file.go:123，skip 与 runtime.Caller 相同
+func caller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "???:0"
+	}
+	return filepath.Base(file) + ":" + strconv.Itoa(line)
+}
+
+// AddSink 为默认的 Printer 添加输出目标
+func AddSink(s Sink) {
+	DefaultStd.Sinks = append(DefaultStd.Sinks, s)
+}
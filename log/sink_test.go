@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSinks(t *testing.T) {
+	var text, jsonl bytes.Buffer
+	printer := New().WithPrefix("commit")
+	printer.Sinks = []Sink{{Writer: &text}, {Writer: &jsonl, Format: FormatJSON, Trace: true}}
+	printer.Trace("细节 %d", 1)
+	printer.Info("已转换 %d 个文档", 2)
+
+	// 没有开启 Trace 的输出目标不输出 Trace 日志
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	assert.Equal(t, 1, len(lines))
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3} INFO   sink_test.go:\d+: \[commit\]已转换 2 个文档$`, lines[0])
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(jsonl.String()), "\n") {
+		var e map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "trace", entries[0]["level"])
+	assert.Equal(t, "info", entries[1]["level"])
+	assert.Equal(t, "commit", entries[1]["prefix"])
+	assert.Equal(t, "已转换 2 个文档", entries[1]["message"])
+	assert.Regexp(t, `^sink_test.go:\d+$`, entries[1]["caller"])
+	assert.NotEmpty(t, entries[1]["time"])
+}
@@ -0,0 +1,35 @@
+package markdown
+
+import (
+	"regexp"
+)
+
+var (
+	// insertionRE pandoc 以 --track-changes=all 转换时生成的插入，markdown 格式为 span，gfm 等格式为 html
+	insertionRE = regexp.MustCompile(`\[([^\[\]]*)\]\{\.insertion[^{}]*\}|<span class="insertion"[^>]*>([^<]*)</span>`)
+	// deletionRE 删除
+	deletionRE = regexp.MustCompile(`\[([^\[\]]*)\]\{\.deletion[^{}]*\}|<span class="deletion"[^>]*>([^<]*)</span>`)
+	// reviewMarkRE 批注与段落修订的标记，批注内容另行导出，正文中直接去掉
+	reviewMarkRE = regexp.MustCompile(`\[[^\[\]]*\]\{\.(comment-start|comment-end|paragraph-insertion|paragraph-deletion)[^{}]*\}|` +
+		`<span class="(comment-start|comment-end|paragraph-insertion|paragraph-deletion)"[^>]*>[^<]*</span>`)
+)
+
+// renderChanges 将修订显示为 CriticMarkup，插入为 {++文字++}，删除为 {--文字--}
+func renderChanges(lines []string, _ Options) []string {
+	return eachLine(lines, func(line string) string {
+		line = reviewMarkRE.ReplaceAllString(line, "")
+		line = replaceChange(insertionRE, line, "{++", "++}")
+		return replaceChange(deletionRE, line, "{--", "--}")
+	})
+}
+
+func replaceChange(re *regexp.Regexp, line, open, close string) string {
+	return re.ReplaceAllStringFunc(line, func(match string) string {
+		m := re.FindStringSubmatch(match)
+		text := m[1] + m[2]
+		if text == "" {
+			return ""
+		}
+		return open + text + close
+	})
+}
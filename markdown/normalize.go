@@ -0,0 +1,369 @@
+// Package markdown 对 pandoc 等工具生成的 markdown 做规范化处理，使同样的内容总是得到同样的输出
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Options 规范化参数
+type Options struct {
+	// Passes 依次执行的规范化步骤，见 PassNames
+	Passes []string
+	// LineWidth 段落换行宽度，0 表示不换行，每个段落一行
+	LineWidth int
+	// SentencePerLine 段落中每个句子单独一行，修改一个词时 diff 只涉及一行
+	SentencePerLine bool
+}
+
+// pass 一个规范化步骤
+type pass struct {
+	name  string
+	apply func(lines []string, opts Options) []string
+}
+
+// passes 全部规范化步骤，按推荐的执行顺序排列
+var passes = []pass{
+	{name: "changes", apply: renderChanges},
+	{name: "attributes", apply: stripAttributes},
+	{name: "escapes", apply: unescape},
+	{name: "headings", apply: normalizeHeadings},
+	{name: "lists", apply: normalizeLists},
+	{name: "tables", apply: normalizeTables},
+	{name: "wrap", apply: rewrap},
+	{name: "whitespace", apply: normalizeWhitespace},
+}
+
+// PassNames 返回全部规范化步骤的名称
+func PassNames() []string {
+	names := make([]string, 0, len(passes))
+	for _, p := range passes {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// Normalize 按 opts 依次执行规范化步骤；步骤总是按 PassNames 的顺序执行，与配置顺序无关
+func Normalize(content string, opts Options) (string, error) {
+	enabled := make(map[string]bool)
+	for _, name := range opts.Passes {
+		enabled[name] = true
+	}
+	for name := range enabled {
+		if !isPass(name) {
+			return "", fmt.Errorf("未知的规范化步骤 %s", name)
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for _, p := range passes {
+		if enabled[p.name] {
+			lines = p.apply(lines, opts)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func isPass(name string) bool {
+	for _, p := range passes {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var fenceRE = regexp.MustCompile("^\\s*(```+|~~~+)")
+
+// protected 返回不能修改的行：开头的 yaml 元数据与代码块
+func protected(lines []string) []bool {
+	result := make([]bool, len(lines))
+	start := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "---" || lines[i] == "..." {
+				for j := 0; j <= i; j++ {
+					result[j] = true
+				}
+				start = i + 1
+				break
+			}
+		}
+	}
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		m := fenceRE.FindStringSubmatch(lines[i])
+		switch {
+		case fence == "" && m != nil:
+			fence = m[1][:3]
+			result[i] = true
+		case fence != "":
+			result[i] = true
+			if m != nil && strings.HasPrefix(m[1], fence) {
+				fence = ""
+			}
+		default:
+		}
+	}
+	return result
+}
+
+// eachLine 对所有可以修改的行执行 fn
+func eachLine(lines []string, fn func(line string) string) []string {
+	skip := protected(lines)
+	for i := range lines {
+		if !skip[i] {
+			lines[i] = fn(lines[i])
+		}
+	}
+	return lines
+}
+
+// attrPattern pandoc 的属性，花括号内以 .class、#id 或 key= 开头，或为标题的 {-}；其他花括号是正文，不能去掉
+const attrPattern = `\{\s*(?:[.#][^{}\s]|[A-Za-z_][\w.-]*=|-\s*\})[^{}]*\}`
+
+var (
+	// spanRE pandoc 的 span 属性，如 [文字]{.underline}
+	spanRE = regexp.MustCompile(`\[([^\[\]]*)\]` + attrPattern)
+	// linkAttrRE 链接与图片的属性，如 ![](a.png){width="1in"}
+	linkAttrRE = regexp.MustCompile(`\)` + attrPattern)
+	// headingAttrRE 标题的属性，如 # 标题 {#id .class}
+	headingAttrRE = regexp.MustCompile(`^(#+ .*?)\s*` + attrPattern + `\s*$`)
+	// trailingAttrRE setext 标题行尾的属性
+	trailingAttrRE = regexp.MustCompile(`^(.*?)\s*` + attrPattern + `\s*$`)
+	// divRE pandoc 的 fenced div，如 ::: {.class}
+	divRE = regexp.MustCompile(`^\s*:::+.*$`)
+)
+
+// stripAttributes 去掉 pandoc 特有的属性，只保留文字
+func stripAttributes(lines []string, _ Options) []string {
+	skip := protected(lines)
+	result := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if skip[i] {
+			result = append(result, line)
+			continue
+		}
+		if divRE.MatchString(line) {
+			continue
+		}
+		for {
+			next := spanRE.ReplaceAllString(line, "$1")
+			if next == line {
+				break
+			}
+			line = next
+		}
+		line = linkAttrRE.ReplaceAllString(line, ")")
+		line = headingAttrRE.ReplaceAllString(line, "$1")
+		if i+1 < len(lines) && setextRE.MatchString(lines[i+1]) {
+			line = trailingAttrRE.ReplaceAllString(line, "$1")
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// unescape 去掉不影响 markdown 语义的转义，如 pandoc 为引号添加的转义
+func unescape(lines []string, _ Options) []string {
+	replacer := strings.NewReplacer(`\'`, `'`, `\"`, `"`)
+	return eachLine(lines, replacer.Replace)
+}
+
+var (
+	atxRE    = regexp.MustCompile(`^(#{1,6})\s*(.*?)\s*#*\s*$`)
+	setextRE = regexp.MustCompile(`^(=+|-+)\s*$`)
+)
+
+// normalizeHeadings 将 setext 标题转换为 # 标题，统一标题内的空格
+func normalizeHeadings(lines []string, _ Options) []string {
+	skip := protected(lines)
+	result := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if skip[i] {
+			result = append(result, line)
+			continue
+		}
+		if i+1 < len(lines) && !skip[i+1] && isText(line) && setextRE.MatchString(lines[i+1]) &&
+			(i == 0 || strings.TrimSpace(lines[i-1]) == "") {
+			level := "#"
+			if strings.HasPrefix(lines[i+1], "-") {
+				level = "##"
+			}
+			result = append(result, level+" "+strings.TrimSpace(line))
+			i++
+			continue
+		}
+		if m := atxRE.FindStringSubmatch(line); m != nil && strings.HasPrefix(line, "#") {
+			if m[2] == "" {
+				line = m[1]
+			} else {
+				line = m[1] + " " + strings.Join(strings.Fields(m[2]), " ")
+			}
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// isText 是否为普通文本行
+func isText(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(trimmed, "#") &&
+		!strings.HasPrefix(trimmed, "|") && !strings.HasPrefix(trimmed, ">") && listRE.FindStringSubmatch(line) == nil
+}
+
+// listRE 列表项，分组依次为缩进、标记、标记后的空格、内容
+var listRE = regexp.MustCompile(`^( *)([-*+]|[0-9]+[.)])( +)(\S.*)$`)
+
+// listLevel 列表项内容的原始列与规范化后的列
+type listLevel struct {
+	from, to int
+}
+
+// normalizeLists 统一无序列表标记为 -，标记后只保留一个空格，并相应调整嵌套内容的缩进
+func normalizeLists(lines []string, _ Options) []string {
+	skip := protected(lines)
+	var stack []listLevel
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		for len(stack) > 0 && indent < stack[len(stack)-1].from {
+			stack = stack[:len(stack)-1]
+		}
+		newIndent := indent
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			newIndent = top.to + indent - top.from
+		}
+		if skip[i] {
+			lines[i] = strings.Repeat(" ", newIndent) + line[indent:]
+			continue
+		}
+		m := listRE.FindStringSubmatch(line)
+		if m == nil || (len(stack) == 0 && indent > 3) {
+			if indent == 0 {
+				stack = nil
+			}
+			lines[i] = strings.Repeat(" ", newIndent) + line[indent:]
+			continue
+		}
+		marker := m[2]
+		if marker == "*" || marker == "+" {
+			marker = "-"
+		}
+		lines[i] = strings.Repeat(" ", newIndent) + marker + " " + m[4]
+		stack = append(stack, listLevel{from: indent + len(m[2]) + len(m[3]), to: newIndent + len(marker) + 1})
+	}
+	return lines
+}
+
+var (
+	separatorCellRE = regexp.MustCompile(`^(:?)-+(:?)$`)
+	// gridBorderRE pandoc 网格表格的边框行，如 +----+ 或 +====+
+	gridBorderRE = regexp.MustCompile(`^\s*\+[-=:]`)
+)
+
+// normalizeTables 去掉管道表格单元格的对齐空格，分隔行统一为 ---；网格表格依赖列对齐，保持不变
+func normalizeTables(lines []string, _ Options) []string {
+	grid := gridTables(lines)
+	skip := protected(lines)
+	for i, line := range lines {
+		if skip[i] || grid[i] {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "|") || !strings.HasSuffix(trimmed, "|") || len(trimmed) < 2 {
+			continue
+		}
+		cells := splitRow(trimmed[1 : len(trimmed)-1])
+		isSeparator := true
+		for n, cell := range cells {
+			cells[n] = strings.TrimSpace(cell)
+			if !separatorCellRE.MatchString(cells[n]) {
+				isSeparator = false
+			}
+		}
+		if isSeparator {
+			for n, cell := range cells {
+				m := separatorCellRE.FindStringSubmatch(cell)
+				cells[n] = m[1] + "---" + m[2]
+			}
+		}
+		lines[i] = "| " + strings.Join(cells, " | ") + " |"
+	}
+	return lines
+}
+
+// gridTables 返回属于网格表格的行：包含边框行的连续非空行
+func gridTables(lines []string) []bool {
+	result := make([]bool, len(lines))
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			continue
+		}
+		for j := start; j < i; j++ {
+			if gridBorderRE.MatchString(lines[j]) {
+				for k := start; k < i; k++ {
+					result[k] = true
+				}
+				break
+			}
+		}
+		start = i + 1
+	}
+	return result
+}
+
+// splitRow 按未转义的 | 拆分表格行
+func splitRow(row string) []string {
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row):
+			cell.WriteByte(row[i])
+			cell.WriteByte(row[i+1])
+			i++
+		case row[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, cell.String())
+}
+
+// normalizeWhitespace 去掉行尾空格，合并连续空行，文件以单个换行结尾
+func normalizeWhitespace(lines []string, _ Options) []string {
+	skip := protected(lines)
+	result := make([]string, 0, len(lines))
+	blank := true
+	for i, line := range lines {
+		if skip[i] {
+			result = append(result, line)
+			blank = false
+			continue
+		}
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		result = append(result, line)
+	}
+	for len(result) > 0 && result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	return append(result, "")
+}
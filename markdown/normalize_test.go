@@ -0,0 +1,151 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const pandocOutput = `---
+title: 'It\'s'
+---
+
+Title {#title .unnumbered}
+=====
+
+##Section   one ##
+
+Some [underlined]{.underline} text with \"quotes\" and
+a ![logo](media/image1.png){width="1in" height="1in"} image.
+第一行中文
+第二行中文。
+
+::: {.note}
+Note inside a div.
+:::
+
+*   first item
+    continued
+    +   nested item
+1.  ordered
+
+|  Name  |  Value |
+|:-------|-------:|
+| a      |   1    |
+
+` + "```" + `
+code   {.keep}
+` + "```" + `
+
+
+
+last line\
+after break
+`
+
+func TestNormalize(t *testing.T) {
+	expected := `---
+title: 'It\'s'
+---
+
+# Title
+
+## Section one
+
+Some underlined text with "quotes" and a ![logo](media/image1.png) image. 第一行中文第二行中文。
+
+Note inside a div.
+
+- first item continued
+  - nested item
+1. ordered
+
+| Name | Value |
+| :--- | ---: |
+| a | 1 |
+
+` + "```" + `
+code   {.keep}
+` + "```" + `
+
+last line\
+after break
+`
+	out, err := Normalize(pandocOutput, Options{Passes: PassNames()})
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, out)
+
+	again, err := Normalize(out, Options{Passes: PassNames()})
+	assert.Nil(t, err)
+	assert.EqualValues(t, out, again)
+
+	_, err = Normalize(pandocOutput, Options{Passes: []string{"unknown"}})
+	assert.NotNil(t, err)
+}
+
+func TestWrap(t *testing.T) {
+	in := "- one two three four five six seven eight\n\n中文段落中文段落中文段落，中文段落。\n"
+	out, err := Normalize(in, Options{Passes: []string{"wrap"}, LineWidth: 16})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "- one two three\n  four five six\n  seven eight\n\n中文段落中文段落\n中文段落，中文段\n落。\n", out)
+
+	// 以 - 开头的单词不能换到行首，否则会变成列表
+	out, err = Normalize("aaaa bbbb - cccc\n", Options{Passes: []string{"wrap"}, LineWidth: 9})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "aaaa bbbb -\ncccc\n", out)
+}
+
+func TestSentencePerLine(t *testing.T) {
+	in := "第一句。第二句！“第三句？”第四句；\n" +
+		"First sentence. Mr. Smith said e.g. this, see `a. b` and [x. y](a.md). Version 1.5 is out!\n\n" +
+		"- 列表项一。列表项二。\n"
+	expected := "第一句。\n第二句！\n“第三句？”\n第四句；\n" +
+		"First sentence.\nMr. Smith said e.g. this, see `a. b` and [x. y](a.md).\nVersion 1.5 is out!\n\n" +
+		"- 列表项一。\n  列表项二。\n"
+	out, err := Normalize(in, Options{Passes: []string{"wrap"}, SentencePerLine: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, out)
+
+	again, err := Normalize(out, Options{Passes: []string{"wrap"}, SentencePerLine: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, out, again)
+
+	// 以数字编号开头的句子不能另起一行，否则会变成有序列表
+	out, err = Normalize("Do this. 2. Then that.\n", Options{Passes: []string{"wrap"}, SentencePerLine: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Do this. 2. Then that.\n", out)
+}
+
+func TestRenderChanges(t *testing.T) {
+	in := `甲方[应在]{.comment-start id="0" author="张三"}[三日]{.deletion author="张三"}[五日]{.insertion author="张三"}内付款[]{.comment-end id="0"}。` + "\n" +
+		`<span class="insertion" author="李四">新增</span><span class="deletion" author="李四">删除</span>` + "\n"
+	out, err := Normalize(in, Options{Passes: PassNames()})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "甲方{--三日--}{++五日++}内付款。 {++新增++}{--删除--}\n", out)
+}
+
+func TestNormalizeGridTable(t *testing.T) {
+	in := "+-------+--------+\n" +
+		"| Name  | Value  |\n" +
+		"+=======+========+\n" +
+		"| a     | 1      |\n" +
+		"+-------+--------+\n\n" +
+		"| Name  | Value  |\n" +
+		"|-------|--------|\n"
+	out, err := Normalize(in, Options{Passes: []string{"tables"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "+-------+--------+\n"+
+		"| Name  | Value  |\n"+
+		"+=======+========+\n"+
+		"| a     | 1      |\n"+
+		"+-------+--------+\n\n"+
+		"| Name | Value |\n"+
+		"| --- | --- |\n", out)
+}
+
+func TestStripAttributes(t *testing.T) {
+	in := "Call f(x){y} and [a]{b}, keep [a]{.underline} and ![](a.png){width=\"1in\"}.\n"
+	out, err := Normalize(in, Options{Passes: []string{"attributes"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Call f(x){y} and [a]{b}, keep a and ![](a.png).\n", out)
+}
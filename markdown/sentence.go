@@ -0,0 +1,102 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// cjkTerminators 中文句末标点，其后直接断句
+	cjkTerminators = "。！？；"
+	// westernTerminators 西文句末标点，其后有空白时断句
+	westernTerminators = ".!?;"
+	// closers 句末标点后紧跟的引号与括号，与句子放在同一行
+	closers = "”’」』）》】\"')]"
+)
+
+// abbreviations 以 . 结尾但通常不是句末的缩写
+var abbreviations = []string{"mr.", "mrs.", "ms.", "dr.", "prof.", "st.", "vs.", "etc.", "e.g.", "i.e.", "no.", "fig."}
+
+// splitSentences 将一段文字拆分为句子，行内代码与链接中的标点不会断句
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	code := false
+	depth := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		switch {
+		case r == '`':
+			code = !code
+			continue
+		case code:
+			continue
+		case r == '[' || r == '(':
+			depth++
+			continue
+		case (r == ']' || r == ')') && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		case strings.ContainsRune(cjkTerminators, r):
+		case strings.ContainsRune(westernTerminators, r):
+			end := skipClosers(text, i)
+			if end < len(text) && text[end] != ' ' || isAbbreviation(text[start:i]) ||
+				blockStartRE.MatchString(strings.TrimSpace(text[start:i])) {
+				continue
+			}
+		default:
+			continue
+		}
+		end := skipClosers(text, i)
+		if sentence := strings.TrimSpace(text[start:end]); sentence != "" {
+			sentences = appendSentence(sentences, sentence)
+		}
+		start, i = end, end
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		sentences = appendSentence(sentences, rest)
+	}
+	return sentences
+}
+
+// skipClosers 跳过 i 处开始的引号与括号，返回其后的位置
+func skipClosers(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !strings.ContainsRune(closers, r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isAbbreviation 判断 s 是否以缩写或单个大写字母（如人名缩写）结尾
+func isAbbreviation(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return false
+	}
+	word := strings.ToLower(fields[len(fields)-1])
+	for _, abbr := range abbreviations {
+		if word == abbr {
+			return true
+		}
+	}
+	last := fields[len(fields)-1]
+	return len(last) == 2 && unicode.IsUpper(rune(last[0]))
+}
+
+// appendSentence 追加句子；句子以列表标记等内容开头时并入上一句，避免放在行首后改变 markdown 语义
+func appendSentence(sentences []string, sentence string) []string {
+	first := strings.Fields(sentence)[0]
+	if len(sentences) > 0 && blockStartRE.MatchString(first) {
+		sentences[len(sentences)-1] += " " + sentence
+		return sentences
+	}
+	return append(sentences, sentence)
+}
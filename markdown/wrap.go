@@ -0,0 +1,204 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// closingPunct 不能出现在行首的标点，换行时与前一个字符放在同一行
+const closingPunct = "，。、；：！？）」』》〉】”’,.;:!?)]"
+
+// blockStartRE 出现在行首时会改变 markdown 语义的内容，换行时不能放在行首
+var blockStartRE = regexp.MustCompile(`^([-+*>|]|#+|[0-9]+[.)]|=+)$`)
+
+// rewrap 重新排版段落：LineWidth 为 0 时每个段落一行，否则按宽度换行，中日韩文字按两个字符宽度计算；
+// SentencePerLine 为 true 时先按句子换行
+func rewrap(lines []string, opts Options) []string {
+	skip := protected(lines)
+	result := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); {
+		prefix, indent, ok := paragraphStart(lines[i])
+		if skip[i] || !ok {
+			result = append(result, lines[i])
+			i++
+			continue
+		}
+		// 收集段落，以行尾的 \ 或两个空格表示的硬换行分段
+		var segments [][]string
+		var segment []string
+		text := lines[i][len(prefix):]
+		for {
+			i++
+			if hardBreak(text) {
+				// 行尾的两个空格会被其他步骤去掉，统一改为 \
+				segment = append(segment, strings.TrimSuffix(strings.TrimSpace(text), `\`)+`\`)
+				segments = append(segments, segment)
+				segment = nil
+			} else {
+				segment = append(segment, strings.TrimSpace(text))
+			}
+			if i >= len(lines) || skip[i] || !isContinuation(lines[i]) {
+				break
+			}
+			text = lines[i]
+		}
+		if segment != nil {
+			segments = append(segments, segment)
+		}
+		for j, seg := range segments {
+			first := indent
+			if j == 0 {
+				first = prefix
+			}
+			result = append(result, layoutParagraph(first, indent, joinLines(seg), opts)...)
+		}
+	}
+	return result
+}
+
+// paragraphStart 判断 line 是否为段落的第一行，返回行首前缀与后续行的缩进
+func paragraphStart(line string) (prefix, indent string, ok bool) {
+	if isText(line) {
+		return "", "", true
+	}
+	if m := listRE.FindStringSubmatch(line); m != nil {
+		prefix = m[1] + m[2] + m[3]
+		return prefix, strings.Repeat(" ", len(prefix)), true
+	}
+	return "", "", false
+}
+
+// isContinuation 判断 line 是否为上一行所在段落的延续
+func isContinuation(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "|") &&
+		!strings.HasPrefix(trimmed, ">") && !strings.HasPrefix(trimmed, "<") &&
+		listRE.FindStringSubmatch(line) == nil && !setextRE.MatchString(line) && !fenceRE.MatchString(line)
+}
+
+func hardBreak(line string) bool {
+	return strings.HasSuffix(line, `\`) || strings.HasSuffix(line, "  ")
+}
+
+// joinLines 合并段落中的行，中日韩文字之间不添加空格
+func joinLines(lines []string) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(sb.String())
+			next, _ := utf8.DecodeRuneInString(line)
+			if !isWide(prev) || !isWide(next) {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// token 换行的最小单位
+type token struct {
+	text string
+	// space token 前是否有空格
+	space bool
+}
+
+// tokenize 拆分为单词，中日韩文字每个字为一个单词，不能出现在行首的标点与前一个单词合并
+func tokenize(text string) []token {
+	var tokens []token
+	for _, field := range strings.Fields(text) {
+		start := len(tokens)
+		var word strings.Builder
+		flush := func() {
+			if word.Len() > 0 {
+				tokens = append(tokens, token{text: word.String(), space: len(tokens) == start})
+				word.Reset()
+			}
+		}
+		for _, r := range field {
+			switch {
+			case strings.ContainsRune(closingPunct, r) && word.Len() == 0 && len(tokens) > start:
+				tokens[len(tokens)-1].text += string(r)
+			case isWide(r):
+				flush()
+				word.WriteRune(r)
+				flush()
+			default:
+				word.WriteRune(r)
+			}
+		}
+		flush()
+	}
+	return tokens
+}
+
+// layoutParagraph 排版一段文字，SentencePerLine 为 true 时每个句子另起一行
+func layoutParagraph(first, indent, text string, opts Options) []string {
+	if !opts.SentencePerLine {
+		return layout(first, indent, text, opts.LineWidth)
+	}
+	var lines []string
+	for i, sentence := range splitSentences(text) {
+		prefix := indent
+		if i == 0 {
+			prefix = first
+		}
+		lines = append(lines, layout(prefix, indent, sentence, opts.LineWidth)...)
+	}
+	if len(lines) == 0 {
+		return []string{first}
+	}
+	return lines
+}
+
+// layout 按宽度排版一段文字，first 为第一行的前缀，indent 为后续行的缩进
+func layout(first, indent, text string, lineWidth int) []string {
+	if lineWidth <= 0 {
+		return []string{first + text}
+	}
+	var lines []string
+	line := first
+	empty := true
+	for _, t := range tokenize(text) {
+		sep := ""
+		if t.space && !empty {
+			sep = " "
+		}
+		if !empty && DisplayWidth(line+sep+t.text) > lineWidth && !blockStartRE.MatchString(t.text) {
+			lines = append(lines, line)
+			line, sep = indent, ""
+		}
+		line += sep + t.text
+		empty = false
+	}
+	return append(lines, line)
+}
+
+// DisplayWidth 返回字符串的显示宽度，中日韩文字与全角字符按两个字符计算
+func DisplayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		if isWide(r) {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func isWide(r rune) bool {
+	if r < utf8.RuneSelf || !unicode.IsPrint(r) {
+		return false
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	default:
+	}
+	return false
+}
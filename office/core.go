@@ -0,0 +1,53 @@
+package office
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// CoreProperties 文档核心属性，对应 docProps/core.xml
+type CoreProperties struct {
+	Title          string `xml:"title"`
+	Subject        string `xml:"subject"`
+	Creator        string `xml:"creator"`
+	LastModifiedBy string `xml:"lastModifiedBy"`
+	Revision       string `xml:"revision"`
+	Created        string `xml:"created"`
+	Modified       string `xml:"modified"`
+}
+
+// ReadCoreProperties 读取文档的核心属性，文档没有核心属性时返回零值
+func ReadCoreProperties(p string) (CoreProperties, error) {
+	var props CoreProperties
+	pkg, err := Open(p)
+	if err != nil {
+		return props, err
+	}
+	defer pkg.Close()
+
+	part := "docProps/core.xml"
+	rel, ok, err := pkg.RelByType("", "/metadata/core-properties")
+	if err != nil {
+		return props, err
+	}
+	if ok {
+		part = rel.Target
+	}
+	if !pkg.Has(part) {
+		return props, nil
+	}
+	content, err := pkg.ReadFile(part)
+	if err != nil {
+		return props, err
+	}
+	if err := xml.Unmarshal(content, &props); err != nil {
+		return props, fmt.Errorf("解析 %s 失败: %v", part, err)
+	}
+	props.Title = strings.TrimSpace(props.Title)
+	props.Subject = strings.TrimSpace(props.Subject)
+	props.Creator = strings.TrimSpace(props.Creator)
+	props.LastModifiedBy = strings.TrimSpace(props.LastModifiedBy)
+	props.Revision = strings.TrimSpace(props.Revision)
+	return props, nil
+}
@@ -0,0 +1,162 @@
+package office
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// customPart 文档没有自定义属性时新建的部件
+	customPart = "docProps/custom.xml"
+	// customRelType 自定义属性部件的关联关系类型
+	customRelType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
+	// customContentType 自定义属性部件的内容类型
+	customContentType = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+	// customFmtID 用户自定义属性固定使用的 fmtid
+	customFmtID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+	// vtNamespace 属性值类型的命名空间
+	vtNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"
+)
+
+var (
+	// pidRE 自定义属性的 pid
+	pidRE = regexp.MustCompile(`\bpid="(\d+)"`)
+	// propertiesEndRE 自定义属性根元素的结束标签
+	propertiesEndRE = regexp.MustCompile(`</(\w+:)?Properties>\s*$`)
+)
+
+// customPartName 返回文档自定义属性部件的名称
+func customPartName(pkg *Package) (string, error) {
+	rel, ok, err := pkg.RelByType("", "/custom-properties")
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return rel.Target, nil
+	}
+	return customPart, nil
+}
+
+// ReadCustomProperty 读取文档自定义属性 name 的值，属性不存在时返回空
+func ReadCustomProperty(p, name string) (string, error) {
+	pkg, err := Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer pkg.Close()
+
+	part, err := customPartName(pkg)
+	if err != nil || !pkg.Has(part) {
+		return "", err
+	}
+	content, err := pkg.ReadFile(part)
+	if err != nil {
+		return "", err
+	}
+	var props struct {
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value struct {
+				Text string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"property"`
+	}
+	if err := xml.Unmarshal(content, &props); err != nil {
+		return "", fmt.Errorf("解析 %s 失败: %v", part, err)
+	}
+	for _, prop := range props.Properties {
+		if prop.Name == name {
+			return strings.TrimSpace(prop.Value.Text), nil
+		}
+	}
+	return "", nil
+}
+
+// WriteCustomProperty 将文档自定义属性 name 设置为字符串 value 并原地改写文档，文档没有自定义属性部件时会新建
+func WriteCustomProperty(p, name, value string) error {
+	pkg, err := Open(p)
+	if err != nil {
+		return err
+	}
+	part, err := customPartName(pkg)
+	exists := pkg.Has(part)
+	pkg.Close()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %v", p, err)
+	}
+	var added map[string][]byte
+	if !exists {
+		added = map[string][]byte{part: []byte(xml.Header + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" ` +
+			`xmlns:vt="` + vtNamespace + `">` + "</Properties>")}
+		added[part] = setProperty(added[part], name, value)
+	}
+	content, err := rewriteZip(original, zip.Deflate, func(n string, data []byte) ([]byte, bool) {
+		switch {
+		case exists && n == part:
+			data = setProperty(data, name, value)
+		case !exists && n == "[Content_Types].xml":
+			override := fmt.Sprintf(`<Override PartName="/%s" ContentType="%s"/>`, part, customContentType)
+			data = insertBefore(data, "</Types>", override)
+		case !exists && n == "_rels/.rels":
+			rel := fmt.Sprintf(`<Relationship Id="rIdGitdocCustom" Type="%s" Target="%s"/>`, customRelType, part)
+			data = insertBefore(data, "</Relationships>", rel)
+		default:
+		}
+		return data, true
+	}, added)
+	if err != nil {
+		return fmt.Errorf("写入 %s 的自定义属性失败: %v", p, err)
+	}
+	return replaceFile(p, content)
+}
+
+// setProperty 在自定义属性部件中设置字符串属性，已存在同名属性时替换其值并保留 pid
+func setProperty(content []byte, name, value string) []byte {
+	escaped := escapeXML(name)
+	propRE := regexp.MustCompile(`(?s)<(\w+:)?property\b[^>]*\bname="` + regexp.QuoteMeta(escaped) + `"[^>]*>.*?</(\w+:)?property>`)
+	pid := 1
+	if loc := propRE.FindIndex(content); loc != nil {
+		if m := pidRE.FindSubmatch(content[loc[0]:loc[1]]); m != nil {
+			pid, _ = strconv.Atoi(string(m[1]))
+		}
+		element := propertyElement(pid, escaped, value)
+		return append(append(append([]byte{}, content[:loc[0]]...), element...), content[loc[1]:]...)
+	}
+	// 用户自定义属性的 pid 从 2 开始
+	for _, m := range pidRE.FindAllSubmatch(content, -1) {
+		if n, _ := strconv.Atoi(string(m[1])); n > pid {
+			pid = n
+		}
+	}
+	element := propertyElement(pid+1, escaped, value)
+	loc := propertiesEndRE.FindIndex(content)
+	if loc == nil {
+		return content
+	}
+	return append(append(append([]byte{}, content[:loc[0]]...), element...), content[loc[0]:]...)
+}
+
+// propertyElement 生成字符串类型的自定义属性元素，值的命名空间单独声明，不依赖根元素的前缀
+func propertyElement(pid int, escapedName, value string) string {
+	return fmt.Sprintf(`<property fmtid="%s" pid="%d" name="%s"><vt:lpwstr xmlns:vt="%s">%s</vt:lpwstr></property>`,
+		customFmtID, pid, escapedName, vtNamespace, escapeXML(value))
+}
+
+// insertBefore 在 content 中最后一个 end 之前插入 s
+func insertBefore(content []byte, end, s string) []byte {
+	i := strings.LastIndex(string(content), end)
+	if i < 0 {
+		return content
+	}
+	return append(append(append([]byte{}, content[:i]...), s...), content[i:]...)
+}
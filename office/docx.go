@@ -0,0 +1,213 @@
+package office
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Comment Word 文档中的一条批注
+type Comment struct {
+	// ID 批注 ID
+	ID string `json:"id"`
+	// Author 批注人
+	Author string `json:"author"`
+	// Date 批注时间，格式与文档中相同，如 2024-01-02T15:04:05Z
+	Date string `json:"date,omitempty"`
+	// Anchor 批注所对应的正文
+	Anchor string `json:"anchor"`
+	// Text 批注内容，段落之间以换行分隔
+	Text string `json:"text"`
+}
+
+// mainDocument 返回 docx 的主文档部件
+func mainDocument(pkg *Package) (string, error) {
+	rel, ok, err := pkg.RelByType("", "/officeDocument")
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return rel.Target, nil
+	}
+	return "word/document.xml", nil
+}
+
+// ReadComments 读取 docx 的全部批注，文档没有批注时返回空
+func ReadComments(p string) ([]Comment, error) {
+	pkg, err := Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	document, err := mainDocument(pkg)
+	if err != nil {
+		return nil, err
+	}
+	rel, ok, err := pkg.RelByType(document, "/comments")
+	if err != nil || !ok {
+		return nil, err
+	}
+	content, err := pkg.ReadFile(rel.Target)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := readComments(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", rel.Target, err)
+	}
+
+	content, err = pkg.ReadFile(document)
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := readAnchors(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", document, err)
+	}
+	for i := range comments {
+		comments[i].Anchor = anchors[comments[i].ID]
+	}
+	return comments, nil
+}
+
+// readComments 解析 comments.xml
+func readComments(content []byte) ([]Comment, error) {
+	var comments []Comment
+	var current *Comment
+	var paragraphs []string
+	var text strings.Builder
+	inText := false
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "comment":
+				current = &Comment{ID: attr(t, "id"), Author: attr(t, "author"), Date: attr(t, "date")}
+				paragraphs = nil
+			case "t":
+				inText = current != nil
+			case "tab":
+				text.WriteString("\t")
+			default:
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if current != nil {
+					paragraphs = append(paragraphs, text.String())
+					text.Reset()
+				}
+			case "comment":
+				if current != nil {
+					current.Text = strings.TrimSpace(strings.Join(paragraphs, "\n"))
+					comments = append(comments, *current)
+					current = nil
+				}
+			default:
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		default:
+		}
+	}
+	return comments, nil
+}
+
+// readAnchors 读取正文中每条批注所对应的文字，返回以批注 ID 为 key 的 map
+func readAnchors(content []byte) (map[string]string, error) {
+	anchors := make(map[string]*strings.Builder)
+	open := make(map[string]bool)
+	inText := false
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "commentRangeStart":
+				id := attr(t, "id")
+				open[id] = true
+				anchors[id] = &strings.Builder{}
+			case "commentRangeEnd":
+				delete(open, attr(t, "id"))
+			case "t":
+				inText = len(open) > 0
+			default:
+			}
+		case xml.EndElement:
+			if t.Name.Local == "t" {
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				for id := range open {
+					anchors[id].Write(t)
+				}
+			}
+		default:
+		}
+	}
+	result := make(map[string]string, len(anchors))
+	for id, sb := range anchors {
+		result[id] = strings.TrimSpace(sb.String())
+	}
+	return result, nil
+}
+
+// CountRevisions 返回 docx 正文中尚未接受或拒绝的修订数量
+func CountRevisions(p string) (int, error) {
+	pkg, err := Open(p)
+	if err != nil {
+		return 0, err
+	}
+	defer pkg.Close()
+
+	document, err := mainDocument(pkg)
+	if err != nil {
+		return 0, err
+	}
+	content, err := pkg.ReadFile(document)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("解析 %s 失败: %v", document, err)
+		}
+		if t, ok := token.(xml.StartElement); ok {
+			switch t.Name.Local {
+			case "ins", "del", "moveFrom", "moveTo":
+				count++
+			default:
+			}
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,321 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeZip 生成测试用的 Office 文档
+func writeZip(t *testing.T, files map[string]string) string {
+	p := filepath.Join(t.TempDir(), "test.zip")
+	f, err := os.Create(p)
+	assert.Nil(t, err)
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		assert.Nil(t, err)
+		_, err = fw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+	return p
+}
+
+const (
+	pptxPresentation = `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"
+ xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<p:sldIdLst><p:sldId id="257" r:id="rId3"/><p:sldId id="256" r:id="rId2"/></p:sldIdLst></p:presentation>`
+	pptxRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide2.xml"/>
+</Relationships>`
+	pptxSlide1 = `<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"
+ xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>标题</a:t></a:r></a:p></p:txBody></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph idx="1"/></p:nvPr></p:nvSpPr><p:txBody>
+<a:p><a:r><a:t>第一点</a:t></a:r></a:p><a:p><a:pPr lvl="1"/><a:r><a:t>子项</a:t></a:r></a:p></p:txBody></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldNum"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>1</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:sld>`
+	pptxSlide1Rels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/>
+</Relationships>`
+	pptxNotes1 = `<p:notes xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"
+ xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>记得讲重点</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:notes>`
+	pptxSlide2 = `<p:sld show="0" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"
+ xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><p:cSld><p:spTree>
+<p:sp><p:txBody><a:p><a:r><a:t>开场</a:t></a:r><a:br/><a:r><a:t>白</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:sld>`
+)
+
+func TestReadSlides(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"ppt/presentation.xml":             pptxPresentation,
+		"ppt/_rels/presentation.xml.rels":  pptxRels,
+		"ppt/slides/slide1.xml":            pptxSlide1,
+		"ppt/slides/_rels/slide1.xml.rels": pptxSlide1Rels,
+		"ppt/notesSlides/notesSlide1.xml":  pptxNotes1,
+		"ppt/slides/slide2.xml":            pptxSlide2,
+	})
+	slides, err := ReadSlides(p)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []Slide{
+		{Index: 1, Paragraphs: []Paragraph{{Text: "开场 白"}}, Hidden: true},
+		{
+			Index:      2,
+			Title:      "标题",
+			Paragraphs: []Paragraph{{Text: "第一点"}, {Level: 1, Text: "子项"}},
+			Notes:      []Paragraph{{Text: "记得讲重点"}},
+		},
+	}, slides)
+}
+
+const (
+	xlsxWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+ xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="预算" sheetId="1" r:id="rId1"/><sheet name="备用" sheetId="2" state="hidden" r:id="rId2"/></sheets></workbook>`
+	xlsxRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>
+</Relationships>`
+	xlsxStrings = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>项目</t></si><si><r><t>金</t></r><r><t>额</t></r><rPh><t>きん</t></rPh></si></sst>`
+	xlsxSheet1 = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>差旅</t></is></c><c r="B3" t="b"><v>1</v></c><c r="C3"><v>1200.5</v></c></row>
+</sheetData></worksheet>`
+	xlsxSheet2 = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`
+)
+
+func TestReadSheets(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"xl/workbook.xml":            xlsxWorkbook,
+		"xl/_rels/workbook.xml.rels": xlsxRels,
+		"xl/sharedStrings.xml":       xlsxStrings,
+		"xl/worksheets/sheet1.xml":   xlsxSheet1,
+		"xl/worksheets/sheet2.xml":   xlsxSheet2,
+	})
+	sheets, err := ReadSheets(p)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []Sheet{
+		{Name: "预算", Rows: [][]string{{"项目", "", "金额"}, {"", "", ""}, {"差旅", "TRUE", "1200.5"}}},
+		{Name: "备用", Rows: [][]string{}, Hidden: true},
+	}, sheets)
+}
+
+func TestReadSheetsSparse(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"xl/workbook.xml":            xlsxWorkbook,
+		"xl/_rels/workbook.xml.rels": xlsxRels,
+		"xl/sharedStrings.xml":       xlsxStrings,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c></row>
+<row r="1048576"><c r="XFD1048576" t="s"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": xlsxSheet2,
+	})
+	sheets, err := ReadSheets(p)
+	assert.Nil(t, err)
+	// 远处的单元格与前面的内容之间只保留 maxSheetGap 个空行与空列
+	rows := sheets[0].Rows
+	assert.Equal(t, maxSheetGap+2, len(rows))
+	assert.Equal(t, maxSheetGap+2, len(rows[0]))
+	assert.Equal(t, "项目", rows[0][0])
+	assert.Equal(t, "金额", rows[maxSheetGap+1][maxSheetGap+1])
+}
+
+func TestReadCoreProperties(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>`,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"
+ xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title> 合同 </dc:title><dc:creator>张三</dc:creator>
+<cp:lastModifiedBy>李四</cp:lastModifiedBy><cp:revision>3</cp:revision></cp:coreProperties>`,
+	})
+	props, err := ReadCoreProperties(p)
+	assert.Nil(t, err)
+	assert.EqualValues(t, CoreProperties{Title: "合同", Creator: "张三", LastModifiedBy: "李四", Revision: "3"}, props)
+
+	props, err = ReadCoreProperties(writeZip(t, map[string]string{"word/document.xml": "<w:document/>"}))
+	assert.Nil(t, err)
+	assert.EqualValues(t, CoreProperties{}, props)
+}
+
+func TestReadComments(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`,
+		"word/_rels/document.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="comments.xml"/>
+</Relationships>`,
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>甲方</w:t></w:r><w:commentRangeStart w:id="0"/><w:r><w:t>应在</w:t></w:r><w:commentRangeStart w:id="1"/>
+<w:r><w:t>三日内</w:t></w:r><w:commentRangeEnd w:id="1"/><w:r><w:t>付款</w:t></w:r><w:commentRangeEnd w:id="0"/></w:p>
+</w:body></w:document>`,
+		"word/comments.xml": `<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:comment w:id="0" w:author="张三" w:date="2024-01-02T03:04:05Z"><w:p><w:r><w:t>改为</w:t></w:r></w:p><w:p><w:r><w:t>五日</w:t></w:r></w:p></w:comment>
+<w:comment w:id="1" w:author="李四"><w:p><w:r><w:t>同意</w:t></w:r></w:p></w:comment>
+</w:comments>`,
+	})
+	comments, err := ReadComments(p)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []Comment{
+		{ID: "0", Author: "张三", Date: "2024-01-02T03:04:05Z", Anchor: "应在三日内付款", Text: "改为\n五日"},
+		{ID: "1", Author: "李四", Anchor: "三日内", Text: "同意"},
+	}, comments)
+}
+
+func TestScrub(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"[Content_Types].xml": `<Types><Override PartName="/customXml/item1.xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="x"/></Types>`,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="x" xmlns:dc="y"><dc:creator>张三</dc:creator>` +
+			`<cp:lastModifiedBy>李四</cp:lastModifiedBy></cp:coreProperties>`,
+		"docProps/app.xml": `<Properties><Company>某某公司</Company><Pages>1</Pages></Properties>`,
+		"word/document.xml": `<w:document><w:p w:rsidR="00A1" w:rsidRDefault="00B2"><w:ins w:id="1" w:author="李四">` +
+			`<w:r><w:t>正文</w:t></w:r></w:ins></w:p></w:document>`,
+		"word/settings.xml":            `<w:settings><w:rsids><w:rsidRoot w:val="00A1"/></w:rsids></w:settings>`,
+		"word/_rels/document.xml.rels": `<Relationships><Relationship Id="rId9" Type="http://x/customXml" Target="../customXml/item1.xml"/></Relationships>`,
+		"customXml/item1.xml":          `<b:Sources/>`,
+	})
+	scrubbed, err := Scrub(p, "作者")
+	assert.Nil(t, err)
+	assert.True(t, scrubbed)
+
+	pkg, err := Open(p)
+	assert.Nil(t, err)
+	defer pkg.Close()
+	assert.False(t, pkg.Has("customXml/item1.xml"))
+	read := func(name string) string {
+		content, err := pkg.ReadFile(name)
+		assert.Nil(t, err)
+		return string(content)
+	}
+	assert.Equal(t, `<Types><Override PartName="/word/document.xml" ContentType="x"/></Types>`, read("[Content_Types].xml"))
+	assert.Equal(t, `<cp:coreProperties xmlns:cp="x" xmlns:dc="y"><dc:creator>作者</dc:creator>`+
+		`<cp:lastModifiedBy>作者</cp:lastModifiedBy></cp:coreProperties>`, read("docProps/core.xml"))
+	assert.Equal(t, `<Properties><Company></Company><Pages>1</Pages></Properties>`, read("docProps/app.xml"))
+	assert.Equal(t, `<w:document><w:p><w:ins w:id="1" w:author="作者"><w:r><w:t>正文</w:t></w:r></w:ins></w:p></w:document>`,
+		read("word/document.xml"))
+	assert.Equal(t, `<w:settings></w:settings>`, read("word/settings.xml"))
+	assert.Equal(t, `<Relationships></Relationships>`, read("word/_rels/document.xml.rels"))
+	for _, f := range pkg.reader.File {
+		assert.True(t, f.Modified.Equal(zipTime), f.Name)
+	}
+
+	// 已经清理过的文档不会再改写
+	scrubbed, err = Scrub(p, "作者")
+	assert.Nil(t, err)
+	assert.False(t, scrubbed)
+}
+
+func TestCleanSmudge(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"word/document.xml": "<w:document>\r\n<w:p w:rsidR=\"00A1\"><w:r><w:t>正文</w:t></w:r></w:p></w:document>",
+		"word/media/a.png":  "\x89PNG\r\n",
+	})
+	original, err := os.ReadFile(p)
+	assert.Nil(t, err)
+
+	cleaned, err := Clean(original)
+	assert.Nil(t, err)
+	reader, err := zip.NewReader(bytes.NewReader(cleaned), int64(len(cleaned)))
+	assert.Nil(t, err)
+	for _, f := range reader.File {
+		assert.Equal(t, zip.Store, f.Method, f.Name)
+		rc, err := f.Open()
+		assert.Nil(t, err)
+		content, err := io.ReadAll(rc)
+		assert.Nil(t, err)
+		rc.Close()
+		if f.Name == "word/document.xml" {
+			assert.Equal(t, "<w:document>\n<w:p><w:r><w:t>正文</w:t></w:r></w:p></w:document>", string(content))
+		} else {
+			assert.Equal(t, "\x89PNG\r\n", string(content))
+		}
+	}
+
+	// clean 的结果再次 clean 或经过 smudge 后再 clean 都不变，git 不会认为文件被修改
+	again, err := Clean(cleaned)
+	assert.Nil(t, err)
+	assert.Equal(t, cleaned, again)
+	smudged, err := Smudge(cleaned)
+	assert.Nil(t, err)
+	again, err = Clean(smudged)
+	assert.Nil(t, err)
+	assert.Equal(t, cleaned, again)
+
+	// 不是 zip 的内容原样返回
+	pointer := []byte("version https://git-lfs.github.com/spec/v1\n")
+	again, err = Clean(pointer)
+	assert.Nil(t, err)
+	assert.Equal(t, pointer, again)
+}
+
+func TestCustomProperty(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"_rels/.rels":         `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`,
+		"word/document.xml":   `<w:document/>`,
+	})
+	value, err := ReadCustomProperty(p, "gitdoc-id")
+	assert.Nil(t, err)
+	assert.Equal(t, "", value)
+
+	// 没有自定义属性部件时新建，并登记内容类型与关联关系
+	assert.Nil(t, WriteCustomProperty(p, "gitdoc-id", "id-1"))
+	value, err = ReadCustomProperty(p, "gitdoc-id")
+	assert.Nil(t, err)
+	assert.Equal(t, "id-1", value)
+	pkg, err := Open(p)
+	assert.Nil(t, err)
+	types, err := pkg.ReadFile("[Content_Types].xml")
+	assert.Nil(t, err)
+	assert.Contains(t, string(types), `<Override PartName="/docProps/custom.xml"`)
+	rel, ok, err := pkg.RelByType("", "/custom-properties")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "docProps/custom.xml", rel.Target)
+	assert.Nil(t, pkg.Close())
+
+	// 已存在的属性替换值并保留 pid，新属性使用更大的 pid
+	assert.Nil(t, WriteCustomProperty(p, "gitdoc-id", "id-2"))
+	assert.Nil(t, WriteCustomProperty(p, "owner", "张三 & 李四"))
+	value, err = ReadCustomProperty(p, "gitdoc-id")
+	assert.Nil(t, err)
+	assert.Equal(t, "id-2", value)
+	value, err = ReadCustomProperty(p, "owner")
+	assert.Nil(t, err)
+	assert.Equal(t, "张三 & 李四", value)
+	pkg, err = Open(p)
+	assert.Nil(t, err)
+	defer pkg.Close()
+	custom, err := pkg.ReadFile("docProps/custom.xml")
+	assert.Nil(t, err)
+	assert.Contains(t, string(custom), `pid="2" name="gitdoc-id"`)
+	assert.Contains(t, string(custom), `pid="3" name="owner"`)
+}
+
+func TestText(t *testing.T) {
+	p := writeZip(t, map[string]string{
+		"[Content_Types].xml": `<Types><Default Extension="xml"/></Types>`,
+		"docProps/core.xml":   `<cp:coreProperties><dc:title>标题</dc:title></cp:coreProperties>`,
+		"word/document.xml":   `<w:document><w:p><w:r><w:t>正文</w:t></w:r></w:p></w:document>`,
+		"word/footer1.xml":    `<w:ftr><w:p><w:r><w:t>页脚</w:t></w:r></w:p></w:ftr>`,
+	})
+	content, err := os.ReadFile(p)
+	assert.Nil(t, err)
+	assert.Equal(t, "正文 页脚 ", Text(content))
+	assert.Equal(t, "纯文本", Text([]byte("纯文本")))
+}
@@ -0,0 +1,133 @@
+// Package office 读取 docx/pptx/xlsx 等 Office Open XML 文档
+package office
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Package 以 zip 形式打开的 Office 文档
+type Package struct {
+	// Path 文档路径
+	Path string
+
+	reader *zip.ReadCloser
+	files  map[string]*zip.File
+}
+
+// Rel 文档内部的关联关系，对应 .rels 文件中的 Relationship
+type Rel struct {
+	// ID 关联关系 ID，如 rId1
+	ID string
+	// Type 关联关系类型
+	Type string
+	// Target 关联的部件，已解析为包内绝对路径
+	Target string
+}
+
+// Open 打开 Office 文档
+func Open(p string) (*Package, error) {
+	reader, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, fmt.Errorf("打开文档 %s 失败: %v", p, err)
+	}
+	pkg := &Package{Path: p, reader: reader, files: make(map[string]*zip.File)}
+	for _, f := range reader.File {
+		pkg.files[f.Name] = f
+	}
+	return pkg, nil
+}
+
+// Close 关闭文档
+func (p *Package) Close() error {
+	return p.reader.Close()
+}
+
+// Has 文档中是否存在部件 name
+func (p *Package) Has(name string) bool {
+	_, ok := p.files[name]
+	return ok
+}
+
+// ReadFile 读取文档中的部件
+func (p *Package) ReadFile(name string) ([]byte, error) {
+	f, ok := p.files[name]
+	if !ok {
+		return nil, fmt.Errorf("文档 %s 中不存在 %s", p.Path, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %v", name, err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Rels 读取部件 part 的关联关系，返回以 ID 为 key 的 map；part 为空时读取包级别的关联关系
+func (p *Package) Rels(part string) (map[string]Rel, error) {
+	dir, file := path.Split(part)
+	relsName := path.Join(dir, "_rels", file+".rels")
+	rels := make(map[string]Rel)
+	if !p.Has(relsName) {
+		return rels, nil
+	}
+	content, err := p.ReadFile(relsName)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Type       string `xml:"Type,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", relsName, err)
+	}
+	for _, r := range doc.Relationships {
+		target := r.Target
+		if r.TargetMode != "External" {
+			target = resolve(dir, target)
+		}
+		rels[r.ID] = Rel{ID: r.ID, Type: r.Type, Target: target}
+	}
+	return rels, nil
+}
+
+// RelByType 返回部件 part 第一个类型以 typeSuffix 结尾的关联关系
+func (p *Package) RelByType(part, typeSuffix string) (Rel, bool, error) {
+	rels, err := p.Rels(part)
+	if err != nil {
+		return Rel{}, false, err
+	}
+	for _, r := range rels {
+		if strings.HasSuffix(r.Type, typeSuffix) {
+			return r, true, nil
+		}
+	}
+	return Rel{}, false, nil
+}
+
+// resolve 将相对 dir 的 target 解析为包内绝对路径
+func resolve(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return strings.TrimPrefix(path.Join("/", dir, target), "/")
+}
+
+// attr 返回元素的属性值，忽略命名空间
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,231 @@
+package office
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsPresentation = "http://schemas.openxmlformats.org/presentationml/2006/main"
+	nsDrawing      = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsRelationship = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// Paragraph 一个文本段落
+type Paragraph struct {
+	// Level 缩进层级，从 0 开始
+	Level int
+	// Text 段落文本
+	Text string
+}
+
+// Slide 一页幻灯片
+type Slide struct {
+	// Index 序号，从 1 开始
+	Index int
+	// Title 标题
+	Title string
+	// Paragraphs 标题之外的文本
+	Paragraphs []Paragraph
+	// Notes 演讲者备注
+	Notes []Paragraph
+	// Hidden 是否为隐藏的幻灯片
+	Hidden bool
+}
+
+// shape 幻灯片中的一个形状
+type shape struct {
+	// placeholder 占位符类型，如 title、body
+	placeholder string
+	paragraphs  []Paragraph
+}
+
+// ReadSlides 按放映顺序读取 pptx 中的全部幻灯片
+func ReadSlides(p string) ([]Slide, error) {
+	pkg, err := Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	const presentation = "ppt/presentation.xml"
+	content, err := pkg.ReadFile(presentation)
+	if err != nil {
+		return nil, err
+	}
+	rels, err := pkg.Rels(presentation)
+	if err != nil {
+		return nil, err
+	}
+
+	var slides []Slide
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", presentation, err)
+		}
+		e, ok := token.(xml.StartElement)
+		if !ok || e.Name.Space != nsPresentation || e.Name.Local != "sldId" {
+			continue
+		}
+		rel, ok := rels[attrNS(e, nsRelationship, "id")]
+		if !ok {
+			continue
+		}
+		slide, err := readSlide(pkg, rel.Target)
+		if err != nil {
+			return nil, err
+		}
+		slide.Index = len(slides) + 1
+		slides = append(slides, slide)
+	}
+	return slides, nil
+}
+
+// readSlide 读取一页幻灯片及其备注
+func readSlide(pkg *Package, part string) (Slide, error) {
+	var slide Slide
+	content, err := pkg.ReadFile(part)
+	if err != nil {
+		return slide, err
+	}
+	shapes, hidden, err := readShapes(content)
+	if err != nil {
+		return slide, fmt.Errorf("解析 %s 失败: %v", part, err)
+	}
+	slide.Hidden = hidden
+	for _, s := range shapes {
+		switch s.placeholder {
+		case "title", "ctrTitle":
+			if slide.Title == "" {
+				slide.Title = joinText(s.paragraphs)
+				continue
+			}
+		case "sldNum", "dt", "ftr", "hdr":
+			continue
+		default:
+		}
+		slide.Paragraphs = append(slide.Paragraphs, s.paragraphs...)
+	}
+
+	rel, ok, err := pkg.RelByType(part, "/notesSlide")
+	if err != nil || !ok {
+		return slide, err
+	}
+	content, err = pkg.ReadFile(rel.Target)
+	if err != nil {
+		return slide, err
+	}
+	shapes, _, err = readShapes(content)
+	if err != nil {
+		return slide, fmt.Errorf("解析 %s 失败: %v", rel.Target, err)
+	}
+	for _, s := range shapes {
+		if s.placeholder == "body" {
+			slide.Notes = append(slide.Notes, s.paragraphs...)
+		}
+	}
+	return slide, nil
+}
+
+// readShapes 读取幻灯片中全部形状的文本，同时返回幻灯片是否隐藏
+func readShapes(content []byte) ([]shape, bool, error) {
+	var (
+		shapes  []shape
+		current *shape
+		para    *Paragraph
+		inText  bool
+		hidden  bool
+		text    strings.Builder
+		loose   = shape{}
+	)
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == nsPresentation && t.Name.Local == "sld":
+				hidden = attr(t, "show") == "0"
+			case t.Name.Space == nsPresentation && t.Name.Local == "sp":
+				current = &shape{}
+			case t.Name.Space == nsPresentation && t.Name.Local == "ph" && current != nil:
+				current.placeholder = attr(t, "type")
+				if current.placeholder == "" {
+					current.placeholder = "body"
+				}
+			case t.Name.Space == nsDrawing && t.Name.Local == "p":
+				para = &Paragraph{}
+				text.Reset()
+			case t.Name.Space == nsDrawing && t.Name.Local == "pPr" && para != nil:
+				para.Level, _ = strconv.Atoi(attr(t, "lvl"))
+			case t.Name.Space == nsDrawing && t.Name.Local == "t":
+				inText = true
+			case t.Name.Space == nsDrawing && t.Name.Local == "br":
+				text.WriteString(" ")
+			default:
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch {
+			case t.Name.Space == nsDrawing && t.Name.Local == "t":
+				inText = false
+			case t.Name.Space == nsDrawing && t.Name.Local == "p" && para != nil:
+				para.Text = strings.TrimSpace(text.String())
+				if para.Text != "" {
+					if current != nil {
+						current.paragraphs = append(current.paragraphs, *para)
+					} else {
+						loose.paragraphs = append(loose.paragraphs, *para)
+					}
+				}
+				para = nil
+			case t.Name.Space == nsPresentation && t.Name.Local == "sp" && current != nil:
+				shapes = append(shapes, *current)
+				current = nil
+			default:
+			}
+		default:
+		}
+	}
+	if len(loose.paragraphs) > 0 {
+		shapes = append(shapes, loose)
+	}
+	return shapes, hidden, nil
+}
+
+// joinText 将多个段落合并为一行
+func joinText(paragraphs []Paragraph) string {
+	texts := make([]string, 0, len(paragraphs))
+	for _, p := range paragraphs {
+		texts = append(texts, p.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+// attrNS 返回元素指定命名空间下的属性值
+func attrNS(e xml.StartElement, space, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
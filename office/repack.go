@@ -0,0 +1,123 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// zipTime 重写后 zip 中每个部件的修改时间，固定为 zip 格式能表示的最早时间
+var zipTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// rewriteZip 按原顺序重写 zip 中的每个部件，全部部件使用 method 压缩并固定修改时间；
+// transform 返回改写后的内容，第二个返回值为 false 时删除该部件；added 中的部件按名称排序追加在最后
+func rewriteZip(content []byte, method uint16, transform func(name string, content []byte) ([]byte, bool),
+	added map[string][]byte) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %v", f.Name, err)
+		}
+		data, keep := transform(f.Name, data)
+		if !keep {
+			continue
+		}
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: f.Name, Method: method, Modified: zipTime})
+		if err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", f.Name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", f.Name, err)
+		}
+	}
+	names := make([]string, 0, len(added))
+	for name := range added {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: zipTime})
+		if err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", name, err)
+		}
+		if _, err := w.Write(added[name]); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Clean 将 Office 文档转换为便于 git 存储的形式：XML 部件去掉 rsid 并统一换行，全部部件不压缩，
+// 使 git 的增量压缩能够生效；内容不是 zip 时原样返回。rsid 只是 Word 记录的编辑会话 ID，不影响文档内容，
+// 去掉后 Smudge 无法还原，检出的文档与暂存前并不逐字节相同
+func Clean(content []byte) ([]byte, error) {
+	if !isZip(content) {
+		return content, nil
+	}
+	return rewriteZip(content, zip.Store, func(name string, data []byte) ([]byte, bool) {
+		if ext := path.Ext(name); ext == ".xml" || ext == ".rels" {
+			data = rsidAttrRE.ReplaceAll(data, nil)
+			data = rsidsRE.ReplaceAll(data, nil)
+			data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		}
+		return data, true
+	}, nil)
+}
+
+// Smudge 将 Clean 的结果重新压缩为常规的 Office 文档，Clean 去掉的 rsid 与换行不会还原；内容不是 zip 时原样返回
+func Smudge(content []byte) ([]byte, error) {
+	if !isZip(content) {
+		return content, nil
+	}
+	return rewriteZip(content, zip.Deflate, func(_ string, data []byte) ([]byte, bool) {
+		return data, true
+	}, nil)
+}
+
+// isZip 内容是否以 zip 文件头开始
+func isZip(content []byte) bool {
+	return bytes.HasPrefix(content, []byte("PK\x03\x04"))
+}
+
+// replaceFile 以 content 替换文件 p，先写入同目录的临时文件再重命名，避免写入失败时损坏文档
+func replaceFile(p string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".gitdoc-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", tmp.Name(), err)
+	}
+	if info, err := os.Stat(p); err == nil {
+		_ = os.Chmod(tmp.Name(), info.Mode())
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("替换 %s 失败: %v", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// rsidAttrRE Word 每次保存时记录的编辑会话 ID，如 w:rsidR="00A1B2C3"
+	rsidAttrRE = regexp.MustCompile(`\s+\w+:rsid\w*="[^"]*"`)
+	// rsidsRE settings.xml 中全部编辑会话 ID 的列表
+	rsidsRE = regexp.MustCompile(`(?s)<\w+:rsids>.*?</\w+:rsids>|<\w+:rsids/>`)
+	// authorAttrRE 修订、批注中的作者与缩写
+	authorAttrRE = regexp.MustCompile(`(\s\w+:(?:author|initials)=")[^"]*(")`)
+	// personRE 核心属性中的作者与最后修改者
+	personRE = regexp.MustCompile(`(<(\w+:)?(?:creator|lastModifiedBy)>)[^<]*(</(\w+:)?(?:creator|lastModifiedBy)>)`)
+	// companyRE 扩展属性中的公司与经理
+	companyRE = regexp.MustCompile(`(<(?:Company|Manager)>)[^<]*(</(?:Company|Manager)>)`)
+	// customXMLRelRE 指向自定义 XML 部件的关联关系
+	customXMLRelRE = regexp.MustCompile(`<Relationship\s[^>]*Type="[^"]*/customXml"[^>]*/>`)
+	// customXMLTypeRE [Content_Types].xml 中自定义 XML 部件的类型声明
+	customXMLTypeRE = regexp.MustCompile(`<Override\s[^>]*PartName="/customXml/[^"]*"[^>]*/>`)
+)
+
+// Scrub 清理 docx 中的个人信息并原地改写：作者、最后修改者、修订与批注的作者替换为 pseudonym（为空时清空），
+// 删除公司、经理、rsid 与自定义 XML 部件，并将 zip 中的修改时间固定；返回文档是否有变化
+func Scrub(p, pseudonym string) (bool, error) {
+	original, err := os.ReadFile(p)
+	if err != nil {
+		return false, fmt.Errorf("读取 %s 失败: %v", p, err)
+	}
+	scrubbed, err := rewriteZip(original, zip.Deflate, func(name string, content []byte) ([]byte, bool) {
+		if strings.HasPrefix(name, "customXml/") {
+			return nil, false
+		}
+		if ext := path.Ext(name); ext == ".xml" || ext == ".rels" {
+			content = scrubPart(name, content, pseudonym)
+		}
+		return content, true
+	}, nil)
+	if err != nil {
+		return false, fmt.Errorf("清理 %s 失败: %v", p, err)
+	}
+	if bytes.Equal(scrubbed, original) {
+		return false, nil
+	}
+
+	if err := replaceFile(p, scrubbed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// scrubPart 清理单个 XML 部件
+func scrubPart(name string, content []byte, pseudonym string) []byte {
+	// 替换模板中的 $ 需要转义
+	value := []byte(strings.ReplaceAll(escapeXML(pseudonym), "$", "$$"))
+	content = rsidAttrRE.ReplaceAll(content, nil)
+	content = rsidsRE.ReplaceAll(content, nil)
+	content = authorAttrRE.ReplaceAll(content, append(append([]byte("${1}"), value...), "${2}"...))
+	content = personRE.ReplaceAll(content, append(append([]byte("${1}"), value...), "${3}"...))
+	content = companyRE.ReplaceAll(content, []byte("${1}${2}"))
+	if name == "[Content_Types].xml" {
+		content = customXMLTypeRE.ReplaceAll(content, nil)
+	}
+	if path.Ext(name) == ".rels" {
+		content = customXMLRelRE.ReplaceAll(content, nil)
+	}
+	return content
+}
+
+// escapeXML 转义 XML 特殊字符，结果可用于属性值与文本
+func escapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
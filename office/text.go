@@ -0,0 +1,52 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Text 返回文档中的文字，用于比较两个文档的内容是否相似：zip 格式的文档（docx、pptx、xlsx、odt 等）
+// 按部件名称顺序拼接各个 XML 部件中的文字，不包括 docProps 等元数据；其他文档原样作为文本返回
+func Text(content []byte) string {
+	if !isZip(content) {
+		return string(content)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return string(content)
+	}
+	files := make([]*zip.File, 0, len(reader.File))
+	for _, f := range reader.File {
+		if path.Ext(f.Name) == ".xml" && !strings.HasPrefix(f.Name, "docProps/") &&
+			!strings.HasPrefix(f.Name, "customXml/") && f.Name != "[Content_Types].xml" {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	var text strings.Builder
+	for _, f := range files {
+		r, err := f.Open()
+		if err != nil {
+			continue
+		}
+		decoder := xml.NewDecoder(r)
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				break
+			}
+			if data, ok := token.(xml.CharData); ok {
+				text.Write(data)
+				text.WriteByte(' ')
+			}
+		}
+		_ = r.Close()
+	}
+	return text.String()
+}
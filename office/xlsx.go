@@ -0,0 +1,287 @@
+package office
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const nsSpreadsheet = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+
+// Sheet 一个工作表
+type Sheet struct {
+	// Name 工作表名称
+	Name string
+	// Rows 单元格文本，按行列排列，每行长度相同；有内容的行列之间较长的连续空行与空列会被压缩
+	Rows [][]string
+	// Hidden 是否为隐藏的工作表
+	Hidden bool
+}
+
+// ReadSheets 按顺序读取 xlsx 中的全部工作表
+func ReadSheets(p string) ([]Sheet, error) {
+	pkg, err := Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	const workbook = "xl/workbook.xml"
+	content, err := pkg.ReadFile(workbook)
+	if err != nil {
+		return nil, err
+	}
+	rels, err := pkg.Rels(workbook)
+	if err != nil {
+		return nil, err
+	}
+	var strs []string
+	if rel, ok, err := pkg.RelByType(workbook, "/sharedStrings"); err != nil {
+		return nil, err
+	} else if ok {
+		if strs, err = readSharedStrings(pkg, rel.Target); err != nil {
+			return nil, err
+		}
+	}
+
+	var doc struct {
+		Sheets []struct {
+			Name  string     `xml:"name,attr"`
+			State string     `xml:"state,attr"`
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", workbook, err)
+	}
+
+	sheets := make([]Sheet, 0, len(doc.Sheets))
+	for _, s := range doc.Sheets {
+		var id string
+		for _, a := range s.Attrs {
+			if a.Name.Space == nsRelationship && a.Name.Local == "id" {
+				id = a.Value
+			}
+		}
+		rel, ok := rels[id]
+		if !ok {
+			continue
+		}
+		rows, err := readSheet(pkg, rel.Target, strs)
+		if err != nil {
+			return nil, err
+		}
+		sheets = append(sheets, Sheet{Name: s.Name, Rows: rows, Hidden: s.State != "" && s.State != "visible"})
+	}
+	return sheets, nil
+}
+
+// readSharedStrings 读取共享字符串表
+func readSharedStrings(pkg *Package, part string) ([]string, error) {
+	content, err := pkg.ReadFile(part)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		strs     []string
+		text     strings.Builder
+		inText   bool
+		phonetic int
+	)
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", part, err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				text.Reset()
+			case "rPh":
+				phonetic++
+			case "t":
+				inText = phonetic == 0
+			default:
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, text.String())
+			case "rPh":
+				phonetic--
+			case "t":
+				inText = false
+			default:
+			}
+		default:
+		}
+	}
+	return strs, nil
+}
+
+// readSheet 读取工作表的单元格
+func readSheet(pkg *Package, part string, strs []string) ([][]string, error) {
+	content, err := pkg.ReadFile(part)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Rows []struct {
+			Index int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline struct {
+					Text string `xml:",innerxml"`
+				} `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", part, err)
+	}
+
+	grid := make(map[int]map[int]string)
+	usedCols := make(map[int]bool)
+	for i, row := range doc.Rows {
+		r := i
+		if row.Index > 0 {
+			r = row.Index - 1
+		}
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			value := cellText(c.Type, c.Value, c.Inline.Text, strs)
+			if value == "" {
+				continue
+			}
+			if grid[r] == nil {
+				grid[r] = make(map[int]string)
+			}
+			grid[r][col] = value
+			usedCols[col] = true
+		}
+	}
+
+	// 按有内容的行列稀疏地生成表格，远处的单个单元格（如 XFD1048576）不会生成巨大的空表格
+	rowIndex, rowCount := compactIndex(keys(grid))
+	colIndex, colCount := compactIndex(keys(usedCols))
+	if rowCount*colCount > maxSheetCells {
+		return nil, fmt.Errorf("%s 有内容的范围为 %d 行 %d 列，超过 %d 个单元格的上限", part, rowCount, colCount, maxSheetCells)
+	}
+	rows := make([][]string, rowCount)
+	for r := range rows {
+		rows[r] = make([]string, colCount)
+	}
+	for r, cells := range grid {
+		for c, value := range cells {
+			rows[rowIndex[r]][colIndex[c]] = value
+		}
+	}
+	return rows, nil
+}
+
+const (
+	// maxSheetGap 有内容的行或列之间保留的连续空行或空列的最大数量
+	maxSheetGap = 10
+	// maxSheetCells 工作表生成的表格最多包含的单元格数
+	maxSheetCells = 4000000
+)
+
+// keys 返回 map 的全部键
+func keys[V any](m map[int]V) []int {
+	result := make([]int, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+// compactIndex 将有内容的行号或列号映射到表格中的位置，返回映射与表格的行数或列数；
+// 之间的空行或空列最多保留 maxSheetGap 个，末尾的空行或空列不保留
+func compactIndex(used []int) (map[int]int, int) {
+	sort.Ints(used)
+	index := make(map[int]int, len(used))
+	next, prev := 0, -1
+	for _, n := range used {
+		next += min(n-prev-1, maxSheetGap)
+		index[n] = next
+		next++
+		prev = n
+	}
+	return index, next
+}
+
+// cellText 按单元格类型返回单元格文本
+func cellText(typ, value, inline string, strs []string) string {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(strs) {
+			return ""
+		}
+		return strs[i]
+	case "b":
+		if value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "inlineStr":
+		return innerText(inline)
+	default:
+	}
+	return value
+}
+
+// innerText 返回 xml 片段中全部 t 元素的文本
+func innerText(fragment string) string {
+	var text strings.Builder
+	inText := false
+	decoder := xml.NewDecoder(strings.NewReader("<x>" + fragment + "</x>"))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		default:
+		}
+	}
+	return text.String()
+}
+
+// columnIndex 将单元格引用中的列字母转换为从 0 开始的列号，如 B3 返回 1
+func columnIndex(ref string) int {
+	col := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+	}
+	return col - 1
+}
@@ -0,0 +1,87 @@
+// Package pipeline 在转换、提交、推送等步骤前后执行用户在配置文件中定义的命令
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/log"
+)
+
+// Step 流水线步骤
+type Step string
+
+const (
+	// PreConvert 转换文档前
+	PreConvert Step = "pre_convert"
+	// PostConvert 转换文档后
+	PostConvert Step = "post_convert"
+	// PreCommit 提交前
+	PreCommit Step = "pre_commit"
+	// PostCommit 提交后
+	PostCommit Step = "post_commit"
+	// PrePush 推送前
+	PrePush Step = "pre_push"
+	// PostPush 推送后
+	PostPush Step = "post_push"
+)
+
+// keys 每个步骤对应的配置项
+var keys = map[Step]string{
+	PreConvert:  config.KeyHooksPreConvert,
+	PostConvert: config.KeyHooksPostConvert,
+	PreCommit:   config.KeyHooksPreCommit,
+	PostCommit:  config.KeyHooksPostCommit,
+	PrePush:     config.KeyHooksPrePush,
+	PostPush:    config.KeyHooksPostPush,
+}
+
+// Context 步骤的上下文，会以环境变量的形式传给用户命令
+type Context struct {
+	// Documents 本次涉及的文档，对应 GITDOC_DOCUMENTS，每行一个
+	Documents []string
+	// Outputs 转换生成的文件，对应 GITDOC_OUTPUTS，每行一个
+	Outputs []string
+	// Message 提交信息，对应 GITDOC_COMMIT_MESSAGE
+	Message string
+}
+
+// env 生成传给用户命令的环境变量
+func (c Context) env(step Step) []string {
+	return []string{
+		"GITDOC_STEP=" + string(step),
+		"GITDOC_DOCUMENTS=" + strings.Join(c.Documents, "\n"),
+		"GITDOC_DOCUMENT_COUNT=" + strconv.Itoa(len(c.Documents)),
+		"GITDOC_OUTPUTS=" + strings.Join(c.Outputs, "\n"),
+		"GITDOC_COMMIT_MESSAGE=" + c.Message,
+	}
+}
+
+// Run 依次执行步骤配置的命令，任一命令失败时返回错误，调用方应终止该步骤
+func Run(step Step, ctx Context) error {
+	commands := viper.GetStringSlice(keys[step])
+	if len(commands) == 0 {
+		return nil
+	}
+	if runtime.GOOS == constant.OSWindows {
+		return fmt.Errorf("暂不支持在 Windows 系统执行 %s", keys[step])
+	}
+	for _, command := range commands {
+		log.Debug("执行 %s: %s", keys[step], command)
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), ctx.env(step)...)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s 执行 %q 失败: %v", keys[step], command, err)
+		}
+	}
+	return nil
+}
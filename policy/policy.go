@@ -0,0 +1,284 @@
+// Package policy 在提交与推送前按配置的规则检查变更的文档
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/convert"
+	"github.com/zhihanggg/gitdoc-cli/git"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/office"
+	"github.com/zhihanggg/gitdoc-cli/scan"
+	"github.com/zhihanggg/gitdoc-cli/utils"
+)
+
+// 规则名称，与 policy 下的配置项同名
+const (
+	RuleMaxSize              = "max_size"
+	RuleRequiredFrontMatter  = "required_front_matter"
+	RuleForbidTrackedChanges = "forbid_tracked_changes"
+	RuleForbidComments       = "forbid_comments"
+	RuleFilenamePattern      = "filename_pattern"
+	RuleFrozen               = "frozen"
+)
+
+// File 变更的文件
+type File struct {
+	// Path 仓库中的路径
+	Path string
+	// Local 可以读取文件内容的本地路径，文件被删除时为空
+	Local string
+}
+
+// Violation 一条违反规则的记录
+type Violation struct {
+	// Rule 规则名称
+	Rule string
+	// Path 违反规则的文件
+	Path string
+	// Message 说明
+	Message string
+}
+
+// Enabled 是否配置了任一规则
+func Enabled() bool {
+	return viper.GetString(config.KeyPolicyMaxSize) != "" ||
+		len(viper.GetStringSlice(config.KeyPolicyRequiredFrontMatter)) > 0 ||
+		viper.GetBool(config.KeyPolicyForbidTrackedChanges) ||
+		viper.GetBool(config.KeyPolicyForbidComments) ||
+		viper.GetString(config.KeyPolicyFilenamePattern) != "" ||
+		len(viper.GetStringSlice(config.KeyPolicyFrozen)) > 0
+}
+
+// Check 按配置的规则检查变更的文件，返回全部违反规则的记录
+func Check(files []File) ([]Violation, error) {
+	var violations []Violation
+	add := func(rule, p, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var maxSize int64
+	if raw := viper.GetString(config.KeyPolicyMaxSize); raw != "" {
+		size, err := utils.ParseSize(raw)
+		if err != nil {
+			return nil, err
+		}
+		maxSize = size
+	}
+	var pattern *regexp.Regexp
+	if raw := viper.GetString(config.KeyPolicyFilenamePattern); raw != "" {
+		re, err := regexp.Compile(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s 不是合法的正则: %v", config.KeyPolicyFilenamePattern, err)
+		}
+		pattern = re
+	}
+	frozen := scan.NewMatcher()
+	frozen.AddPatterns("", viper.GetStringSlice(config.KeyPolicyFrozen))
+	required := viper.GetStringSlice(config.KeyPolicyRequiredFrontMatter)
+	forbidChanges := viper.GetBool(config.KeyPolicyForbidTrackedChanges)
+	forbidComments := viper.GetBool(config.KeyPolicyForbidComments)
+
+	// 变更的文档生成的 markdown
+	outputs := make(map[string]bool)
+	for _, f := range files {
+		if _, ok := convert.Lookup(f.Path); ok && f.Local != "" {
+			outputs[filepath.ToSlash(convert.OutputPath(f.Path))] = true
+		}
+	}
+
+	for _, f := range files {
+		if frozen.Ignored(f.Path) {
+			if f.Local == "" {
+				add(RuleFrozen, f.Path, "路径已冻结，不能删除")
+			} else {
+				add(RuleFrozen, f.Path, "路径已冻结，不能修改")
+			}
+		}
+		if f.Local == "" {
+			continue
+		}
+		if maxSize > 0 {
+			info, err := os.Stat(f.Local)
+			if err != nil {
+				return nil, fmt.Errorf("读取 %s 失败: %v", f.Path, err)
+			}
+			if info.Size() > maxSize {
+				add(RuleMaxSize, f.Path, "文件大小 %s 超过上限 %s", utils.FormatSize(info.Size()), utils.FormatSize(maxSize))
+			}
+		}
+		if len(required) > 0 && outputs[filepath.ToSlash(f.Path)] {
+			missing, err := missingFields(f.Local, required)
+			if err != nil {
+				return nil, fmt.Errorf("读取 %s 失败: %v", f.Path, err)
+			}
+			if len(missing) > 0 {
+				add(RuleRequiredFrontMatter, f.Path, "元数据缺少字段 %s", strings.Join(missing, ", "))
+			}
+		}
+		if _, ok := convert.Lookup(f.Path); !ok {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(path.Base(filepath.ToSlash(f.Path))) {
+			add(RuleFilenamePattern, f.Path, "文件名不匹配 %s", pattern.String())
+		}
+		if !strings.EqualFold(filepath.Ext(f.Path), ".docx") {
+			continue
+		}
+		if forbidChanges {
+			count, err := office.CountRevisions(f.Local)
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				add(RuleForbidTrackedChanges, f.Path, "有 %d 处修订尚未接受或拒绝", count)
+			}
+		}
+		if forbidComments {
+			comments, err := office.ReadComments(f.Local)
+			if err != nil {
+				return nil, err
+			}
+			if len(comments) > 0 {
+				add(RuleForbidComments, f.Path, "有 %d 条批注尚未删除", len(comments))
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return violations, nil
+}
+
+// missingFields 返回 markdown 元数据中缺少或为空的字段
+func missingFields(p string, required []string) ([]string, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := convert.FrontMatterFields(content)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, name := range required {
+		if value, ok := fields[name]; !ok || value == nil || fmt.Sprint(value) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// Report 输出全部违反规则的记录，有记录时返回错误
+func Report(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	log.Error("提交规则检查未通过，共 %d 个问题:", len(violations))
+	for _, v := range violations {
+		log.Error("  [%s] %s: %s", v.Rule, v.Path, v.Message)
+	}
+	return fmt.Errorf("有 %d 个问题违反提交规则，可以使用 --no-verify 跳过检查", len(violations))
+}
+
+// Staged 返回暂存区中变更的文件，暂存的文件内容会导出到临时目录，检查完成后需要调用 cleanup 删除；
+// 检查的是将要提交的内容，而不是工作区中可能在暂存后又修改过的文件
+func Staged() (files []File, cleanup func(), err error) {
+	changed, err := git.StagedFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, err := git.StagedDeleted()
+	if err != nil {
+		return nil, nil, err
+	}
+	// git show :path 读取暂存区中的文件
+	return export("", changed, deleted)
+}
+
+// Revision 返回 from 到 to 之间变更的文件，文件内容会导出到临时目录，检查完成后需要调用 cleanup 删除
+func Revision(from, to string) (files []File, cleanup func(), err error) {
+	changed, err := git.ChangedFiles(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, err := git.DeletedFiles(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return export(to, changed, deleted)
+}
+
+// export 将提交 rev 中的文件 changed 导出到临时目录，rev 为空时导出暂存区中的文件
+func export(rev string, changed, deleted []string) (files []File, cleanup func(), err error) {
+	dir, err := os.MkdirTemp("", "gitdoc-policy-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	cleanup = func() {
+		_ = os.RemoveAll(dir)
+	}
+	for _, p := range changed {
+		content, err := git.Show(rev, p)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		local := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(local, content, 0644); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("写入 %s 失败: %v", local, err)
+		}
+		files = append(files, File{Path: p, Local: local})
+	}
+	for _, p := range deleted {
+		files = append(files, File{Path: p})
+	}
+	return files, cleanup, nil
+}
+
+// VerifyStaged 检查暂存区中变更的文件
+func VerifyStaged() error {
+	if !Enabled() {
+		return nil
+	}
+	files, cleanup, err := Staged()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	violations, err := Check(files)
+	if err != nil {
+		return err
+	}
+	return Report(violations)
+}
+
+// VerifyRevision 检查 from 到 to 之间变更的文件，from 为空时检查 to 中的全部文件
+func VerifyRevision(from, to string) error {
+	if !Enabled() {
+		return nil
+	}
+	files, cleanup, err := Revision(from, to)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	violations, err := Check(files)
+	if err != nil {
+		return err
+	}
+	return Report(violations)
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/zhihanggg/gitdoc-cli/config"
+)
+
+func TestCheck(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	assert.False(t, Enabled())
+
+	viper.Set(config.KeyPolicyMaxSize, "1KB")
+	viper.Set(config.KeyPolicyRequiredFrontMatter, []string{"source", "title"})
+	viper.Set(config.KeyPolicyFilenamePattern, `^[a-z0-9-]+\.(docx|pptx)$`)
+	viper.Set(config.KeyPolicyFrozen, []string{"archive/**"})
+	assert.True(t, Enabled())
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		assert.Nil(t, os.WriteFile(p, []byte(content), 0644))
+		return p
+	}
+	files := []File{
+		{Path: "docs/合同.pptx", Local: write("a.pptx", string(make([]byte, 2048)))},
+		{Path: "docs/合同.md", Local: write("a.md", "---\nsource: docs/合同.pptx\ntitle: \"\"\n---\n")},
+		{Path: "docs/plan.pptx", Local: write("b.pptx", "x")},
+		{Path: "docs/plan.md", Local: write("b.md", "---\nsource: docs/plan.pptx\ntitle: 计划\n---\n")},
+		{Path: "notes.md", Local: write("c.md", "# 手写的文档\n")},
+		{Path: "archive/old.pptx"},
+	}
+	violations, err := Check(files)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []Violation{
+		{Rule: RuleFrozen, Path: "archive/old.pptx", Message: "路径已冻结，不能删除"},
+		{Rule: RuleRequiredFrontMatter, Path: "docs/合同.md", Message: "元数据缺少字段 title"},
+		{Rule: RuleMaxSize, Path: "docs/合同.pptx", Message: "文件大小 2.0KB 超过上限 1.0KB"},
+		{Rule: RuleFilenamePattern, Path: "docs/合同.pptx", Message: `文件名不匹配 ^[a-z0-9-]+\.(docx|pptx)$`},
+	}, violations)
+	assert.NotNil(t, Report(violations))
+	assert.Nil(t, Report(nil))
+}
+
+func TestVerifyRevisionNewBranch(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	viper.Set(config.KeyPolicyFrozen, []string{"archive/**"})
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(output))
+	}
+	run("init", "-q", "--bare", filepath.Join(dir, "remote.git"))
+	run("init", "-q", filepath.Join(dir, "work"))
+	assert.Nil(t, os.Chdir(filepath.Join(dir, "work")))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.Nil(t, os.MkdirAll("archive", 0755))
+	assert.Nil(t, os.WriteFile("archive/old.txt", []byte("旧文档"), 0644))
+	run("add", "-A")
+	run("commit", "-q", "-m", "init")
+	run("remote", "add", "origin", filepath.Join(dir, "remote.git"))
+	run("push", "-q", "origin", "HEAD:main")
+
+	// 新分支没有上游，只检查分支上新增的提交，不会把已冻结的旧文件当作修改
+	run("checkout", "-q", "-b", "feature")
+	assert.Nil(t, os.WriteFile("notes.txt", []byte("新文档"), 0644))
+	run("add", "-A")
+	run("commit", "-q", "-m", "notes")
+	assert.Nil(t, VerifyRevision("", "HEAD"))
+
+	assert.Nil(t, os.WriteFile("archive/old.txt", []byte("修改"), 0644))
+	run("commit", "-q", "-am", "modify")
+	assert.NotNil(t, VerifyRevision("", "HEAD"))
+}
+
+func TestVerifyStagedContent(t *testing.T) {
+	config.SetDefaults()
+	defer viper.Reset()
+	viper.Set(config.KeyPolicyMaxSize, "1KB")
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.Nil(t, exec.Command("git", "init", "-q", dir).Run())
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	assert.Nil(t, os.Chdir(filepath.Join(dir, "docs")))
+	defer func() { _ = os.Chdir(wd) }()
+
+	// 检查暂存的内容，暂存后在工作区中的修改不影响检查；在子目录中执行时路径仍相对于仓库根目录
+	assert.Nil(t, os.WriteFile("a.docx", make([]byte, 2048), 0644))
+	assert.Nil(t, exec.Command("git", "add", "a.docx").Run())
+	assert.Nil(t, os.WriteFile("a.docx", []byte("x"), 0644))
+	assert.NotNil(t, VerifyStaged())
+
+	assert.Nil(t, exec.Command("git", "add", "a.docx").Run())
+	assert.Nil(t, os.WriteFile("a.docx", make([]byte, 2048), 0644))
+	assert.Nil(t, VerifyStaged())
+}
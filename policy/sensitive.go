@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/log"
+	"github.com/zhihanggg/gitdoc-cli/sensitive"
+)
+
+// pattern sensitive.patterns 中的一条自定义规则
+type pattern struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+// newScanner 按 sensitive 配置创建敏感信息检查器
+func newScanner() (*sensitive.Scanner, error) {
+	var patterns []pattern
+	if err := viper.UnmarshalKey(config.KeySensitivePatterns, &patterns); err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %v", config.KeySensitivePatterns, err)
+	}
+	custom := make([]sensitive.Rule, 0, len(patterns))
+	for _, p := range patterns {
+		rule, err := sensitive.NewRule(p.Name, p.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		custom = append(custom, rule)
+	}
+	return sensitive.NewScanner(viper.GetStringSlice(config.KeySensitiveRules), custom,
+		viper.GetStringSlice(config.KeySensitiveAllowlist))
+}
+
+// ScanSensitive 检查转换生成的文件中的敏感信息，不存在的文件会跳过
+func ScanSensitive(paths []string) ([]sensitive.Finding, error) {
+	scanner, err := newScanner()
+	if err != nil || scanner.Empty() {
+		return nil, err
+	}
+	var findings []sensitive.Finding
+	for _, p := range paths {
+		if info, err := os.Stat(p); err != nil || info.IsDir() {
+			continue
+		}
+		found, err := scanner.ScanFile(p)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, found...)
+	}
+	return findings, nil
+}
+
+// ReportSensitive 输出全部疑似敏感信息的位置，命中的文本会被遮盖，有记录时返回错误
+func ReportSensitive(findings []sensitive.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	log.Error("发现 %d 处疑似敏感信息:", len(findings))
+	for _, f := range findings {
+		log.Error("  %s:%d [%s] %s", f.Path, f.Line, f.Rule, sensitive.Mask(f.Match))
+	}
+	return fmt.Errorf("发现 %d 处疑似敏感信息，请在源文档中删除后重新提交；误报可以加入 %s，或使用 --no-verify 跳过检查",
+		len(findings), config.KeySensitiveAllowlist)
+}
+
+// VerifySensitive 检查转换生成的文件，发现敏感信息时返回错误
+func VerifySensitive(paths []string) error {
+	findings, err := ScanSensitive(paths)
+	if err != nil {
+		return err
+	}
+	return ReportSensitive(findings)
+}
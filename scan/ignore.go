@@ -0,0 +1,182 @@
+package scan
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// rule 一条 gitignore 风格的忽略规则
+type rule struct {
+	// base 规则所在目录，相对扫描根目录，"" 表示根目录
+	base string
+	// re 规则对应的正则
+	re *regexp.Regexp
+	// negate 以 ! 开头的规则，表示重新包含
+	negate bool
+	// dirOnly 以 / 结尾的规则，只匹配目录
+	dirOnly bool
+}
+
+// Matcher gitignore 风格的路径匹配器，后添加的规则优先级更高
+type Matcher struct {
+	rules []rule
+	// loaded 已经读取过忽略文件的目录
+	loaded map[string]bool
+}
+
+// NewMatcher 新建一个匹配器
+func NewMatcher() *Matcher {
+	return &Matcher{loaded: make(map[string]bool)}
+}
+
+// AddPatterns 添加 base 目录下生效的规则，语法与 .gitignore 相同
+func (m *Matcher) AddPatterns(base string, patterns []string) {
+	base = normalize(base)
+	for _, pattern := range patterns {
+		if r, ok := compile(base, pattern); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+}
+
+// AddFile 读取 dir 目录下的忽略文件，文件不存在时忽略
+func (m *Matcher) AddFile(dir, name string) error {
+	file, err := os.Open(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	m.AddPatterns(dir, patterns)
+	return scanner.Err()
+}
+
+// Match 返回 p 本身是否被忽略，不检查上级目录
+func (m *Matcher) Match(p string, isDir bool) bool {
+	p = normalize(p)
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		rel := p
+		if r.base != "" {
+			if !strings.HasPrefix(p, r.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(p, r.base+"/")
+		}
+		if r.re.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// Ignored 返回文件 p 或其任一上级目录是否被忽略
+func (m *Matcher) Ignored(p string) bool {
+	p = normalize(p)
+	parts := strings.Split(p, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.Match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.Match(p, false)
+}
+
+// normalize 统一为以 / 分隔、不带 ./ 前缀的相对路径
+func normalize(p string) string {
+	p = path.Clean(filepath.ToSlash(p))
+	if p == "." {
+		return ""
+	}
+	return strings.TrimPrefix(p, "./")
+}
+
+// compile 将 gitignore 规则转换为正则
+func compile(base, pattern string) (rule, bool) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return rule{}, false
+	}
+	r := rule{base: base}
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return rule{}, false
+	}
+
+	// 包含 / 的规则相对于 base 目录，否则匹配任意层级
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return rule{}, false
+	}
+	r.re = re
+	return r, true
+}
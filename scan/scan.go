@@ -0,0 +1,192 @@
+// Package scan 按 gitignore 风格的规则扫描仓库中的文档
+package scan
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zhihanggg/gitdoc-cli/config"
+	"github.com/zhihanggg/gitdoc-cli/constant"
+	"github.com/zhihanggg/gitdoc-cli/log"
+)
+
+// DefaultIgnore 默认忽略的文件，包括版本库目录、依赖目录以及 Office 编辑时产生的锁文件和临时文件
+var DefaultIgnore = []string{
+	".git/",
+	"node_modules/",
+	"~$*",
+	".~lock.*#",
+	"~*.tmp",
+	".DS_Store",
+}
+
+// Options 扫描参数
+type Options struct {
+	// Roots 扫描的文档根目录
+	Roots []string
+	// Ignore 额外的忽略规则，语法与 .gitignore 相同
+	Ignore []string
+	// UseGitignore 是否同时遵循 .gitignore
+	UseGitignore bool
+}
+
+// OptionsFromConfig 根据配置生成扫描参数
+func OptionsFromConfig() Options {
+	return Options{
+		Roots:        viper.GetStringSlice(config.KeyScanRoots),
+		Ignore:       viper.GetStringSlice(config.KeyScanIgnore),
+		UseGitignore: viper.GetBool(config.KeyScanUseGitignore),
+	}
+}
+
+// ignoreFiles 返回需要读取的忽略文件
+func (o Options) ignoreFiles() []string {
+	if o.UseGitignore {
+		return []string{".gitignore", constant.IgnoreFile}
+	}
+	return []string{constant.IgnoreFile}
+}
+
+// roots 返回扫描的根目录，未配置时为当前目录
+func (o Options) roots() []string {
+	if len(o.Roots) == 0 {
+		return []string{"."}
+	}
+	return o.Roots
+}
+
+// newMatcher 创建包含默认规则与配置规则的匹配器
+func (o Options) newMatcher() *Matcher {
+	m := NewMatcher()
+	m.AddPatterns("", DefaultIgnore)
+	m.AddPatterns("", o.Ignore)
+	return m
+}
+
+// loadDir 读取目录下的忽略文件，每个目录只读取一次
+func (o Options) loadDir(m *Matcher, dir string) {
+	dir = normalize(dir)
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+	for _, name := range o.ignoreFiles() {
+		if err := m.AddFile(filepath.FromSlash(orDot(dir)), name); err != nil {
+			log.Warn("读取忽略文件 %s 失败: %v", filepath.Join(orDot(dir), name), err)
+		}
+	}
+}
+
+// loadAncestors 读取 p 所有上级目录下的忽略文件
+func (o Options) loadAncestors(m *Matcher, p string) {
+	o.loadDir(m, "")
+	parts := strings.Split(normalize(p), "/")
+	for i := 1; i < len(parts); i++ {
+		o.loadDir(m, strings.Join(parts[:i], "/"))
+	}
+}
+
+// Documents 扫描根目录下扩展名属于 extensions 的文档，返回相对当前目录的路径
+func Documents(opts Options, extensions []string) ([]string, error) {
+	var files []string
+	err := walk(opts, func(p string, isDir bool) {
+		if !isDir && hasExt(p, extensions) {
+			files = append(files, p)
+		}
+	})
+	return files, err
+}
+
+// Dirs 返回根目录下全部未被忽略的目录，包括根目录本身
+func Dirs(opts Options) ([]string, error) {
+	var dirs []string
+	err := walk(opts, func(p string, isDir bool) {
+		if isDir {
+			dirs = append(dirs, p)
+		}
+	})
+	return dirs, err
+}
+
+// walk 遍历根目录下全部未被忽略的目录和文件
+func walk(opts Options, fn func(p string, isDir bool)) error {
+	m := opts.newMatcher()
+	seen := make(map[string]bool)
+	for _, root := range opts.roots() {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			log.Warn("文档目录 %s 不存在，已跳过", root)
+			continue
+		}
+		opts.loadAncestors(m, filepath.Join(root, "_"))
+		if rel := normalize(root); rel != "" && m.Ignored(rel+"/_") {
+			continue
+		}
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel := normalize(p)
+			if seen[rel] {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			seen[rel] = true
+			if d.IsDir() {
+				if rel != "" && m.Match(rel, true) {
+					return filepath.SkipDir
+				}
+				opts.loadDir(m, rel)
+				fn(p, true)
+				return nil
+			}
+			if !m.Ignored(rel) {
+				fn(p, false)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ignored 返回路径 p 是否会被扫描忽略，用于判断单个文件
+func Ignored(opts Options, p string) bool {
+	m := opts.newMatcher()
+	opts.loadAncestors(m, p)
+	if m.Ignored(p) {
+		return true
+	}
+	for _, root := range opts.roots() {
+		root = normalize(root)
+		rel := normalize(p)
+		if root == "" || rel == root || strings.HasPrefix(rel, root+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// hasExt 文件扩展名是否属于 extensions，不区分大小写
+func hasExt(p string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(p))
+	for _, validExt := range extensions {
+		if ext == validExt {
+			return true
+		}
+	}
+	return false
+}
+
+func orDot(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
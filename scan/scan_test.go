@@ -0,0 +1,78 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher()
+	m.AddPatterns("", []string{
+		"# 注释",
+		"*.tmp",
+		"build/",
+		"/draft.docx",
+		"docs/**/old",
+		"archive/*.docx",
+		"!archive/keep.docx",
+	})
+	m.AddPatterns("sub", []string{"local.docx"})
+
+	cases := map[string]bool{
+		"a.tmp":              true,
+		"x/y/a.tmp":          true,
+		"build/a.docx":       true,
+		"x/build/a.docx":     true,
+		"draft.docx":         true,
+		"x/draft.docx":       false,
+		"docs/old/a.docx":    true,
+		"docs/a/b/old/a.doc": true,
+		"archive/a.docx":     true,
+		"archive/keep.docx":  false,
+		"sub/local.docx":     true,
+		"local.docx":         false,
+		"a.docx":             false,
+	}
+	for p, ignored := range cases {
+		assert.EqualValues(t, ignored, m.Ignored(p), p)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"a.docx",
+		"~$a.docx",
+		".git/b.docx",
+		"node_modules/c.docx",
+		"docs/d.DOCX",
+		"docs/e.txt",
+		"docs/private/f.docx",
+		"other/g.docx",
+	}
+	for _, f := range files {
+		p := filepath.Join(dir, f)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, nil, 0644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "docs", ".gitdocignore"), []byte("private/\n"), 0644))
+
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	docs, err := Documents(Options{}, []string{".docx"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"a.docx", "docs/d.DOCX", "other/g.docx"}, docs)
+
+	docs, err = Documents(Options{Roots: []string{"docs"}, Ignore: []string{"d.*"}}, []string{".docx"})
+	assert.Nil(t, err)
+	assert.Empty(t, docs)
+
+	assert.True(t, Ignored(Options{}, "docs/private/f.docx"))
+	assert.True(t, Ignored(Options{Roots: []string{"docs"}}, "other/g.docx"))
+	assert.False(t, Ignored(Options{Roots: []string{"docs"}}, "docs/d.DOCX"))
+}
@@ -0,0 +1,218 @@
+// Package sensitive 检查文本中的身份证号、手机号、银行卡号、邮箱与密钥等敏感信息
+package sensitive
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// 内置规则名称
+const (
+	RuleIDCard   = "id_card"
+	RulePhone    = "phone"
+	RuleBankCard = "bank_card"
+	RuleEmail    = "email"
+	RuleSecret   = "secret"
+)
+
+// Rule 一条检查规则
+type Rule struct {
+	// Name 规则名称
+	Name string
+
+	re *regexp.Regexp
+	// valid 对匹配结果做进一步校验，如身份证与银行卡的校验位，为空时不校验
+	valid func(match string) bool
+}
+
+// builtin 内置规则
+var builtin = []Rule{
+	{
+		Name:  RuleIDCard,
+		re:    regexp.MustCompile(`\b[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+		valid: validIDCard,
+	},
+	{
+		Name: RulePhone,
+		re:   regexp.MustCompile(`\b(?:\+?86[- ]?)?1[3-9]\d{9}\b`),
+	},
+	{
+		Name:  RuleBankCard,
+		re:    regexp.MustCompile(`\b\d{4}(?:[ -]?\d{4}){2,3}(?:[ -]?\d{1,3})?\b`),
+		valid: validBankCard,
+	},
+	{
+		Name: RuleEmail,
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		Name: RuleSecret,
+		re: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----|\bAKIA[0-9A-Z]{16}\b|` +
+			`\bgh[pousr]_[A-Za-z0-9]{36}\b|(?i)(?:password|passwd|secret|token|api[_-]?key|密码|口令)\s*[:=：]\s*\S{6,}`),
+	},
+}
+
+// BuiltinNames 返回全部内置规则的名称
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtin))
+	for _, r := range builtin {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// NewRule 以正则 pattern 创建自定义规则
+func NewRule(name, pattern string) (Rule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("规则 %s 的正则不合法: %v", name, err)
+	}
+	return Rule{Name: name, re: re}, nil
+}
+
+// Finding 一处疑似敏感信息
+type Finding struct {
+	// Rule 命中的规则
+	Rule string
+	// Path 文件路径
+	Path string
+	// Line 行号，从 1 开始
+	Line int
+	// Match 命中的文本
+	Match string
+}
+
+// Scanner 敏感信息检查器
+type Scanner struct {
+	rules []Rule
+	allow []*regexp.Regexp
+}
+
+// NewScanner 创建检查器，names 为启用的内置规则，custom 为自定义规则，命中 allowlist 中任一正则的文本不会报告
+func NewScanner(names []string, custom []Rule, allowlist []string) (*Scanner, error) {
+	s := &Scanner{}
+	for _, name := range names {
+		found := false
+		for _, r := range builtin {
+			if r.Name == name {
+				s.rules = append(s.rules, r)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("不存在内置规则 %s，可选: %s", name, strings.Join(BuiltinNames(), ", "))
+		}
+	}
+	s.rules = append(s.rules, custom...)
+	for _, pattern := range allowlist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("白名单 %q 不是合法的正则: %v", pattern, err)
+		}
+		s.allow = append(s.allow, re)
+	}
+	return s, nil
+}
+
+// Empty 是否没有任何规则
+func (s *Scanner) Empty() bool {
+	return len(s.rules) == 0
+}
+
+// Scan 检查文本 content，p 为报告中使用的文件路径
+func (s *Scanner) Scan(p string, content []byte) []Finding {
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		// 同一段文本只报告第一个命中的规则，如身份证号不会再报告为银行卡号
+		var covered [][]int
+		for _, r := range s.rules {
+			for _, loc := range r.re.FindAllStringIndex(text, -1) {
+				match := text[loc[0]:loc[1]]
+				if overlaps(covered, loc) || (r.valid != nil && !r.valid(match)) || s.allowed(match) {
+					continue
+				}
+				covered = append(covered, loc)
+				findings = append(findings, Finding{Rule: r.Name, Path: p, Line: line, Match: match})
+			}
+		}
+	}
+	return findings
+}
+
+// ScanFile 检查文件 p
+func (s *Scanner) ScanFile(p string) ([]Finding, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %v", p, err)
+	}
+	return s.Scan(p, content), nil
+}
+
+func (s *Scanner) allowed(match string) bool {
+	for _, re := range s.allow {
+		if re.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(covered [][]int, loc []int) bool {
+	for _, c := range covered {
+		if loc[0] < c[1] && c[0] < loc[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask 遮盖命中的文本，只保留首尾少量字符，避免在输出中泄露完整的敏感信息
+func Mask(match string) string {
+	runes := []rune(match)
+	switch {
+	case len(runes) <= 4:
+		return strings.Repeat("*", len(runes))
+	case len(runes) <= 10:
+		return string(runes[:1]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1:])
+	default:
+		return string(runes[:3]) + strings.Repeat("*", len(runes)-7) + string(runes[len(runes)-4:])
+	}
+}
+
+// validIDCard 校验 18 位身份证号的校验位
+func validIDCard(id string) bool {
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	const checks = "10X98765432"
+	sum := 0
+	for i, w := range weights {
+		sum += int(id[i]-'0') * w
+	}
+	return strings.ToUpper(id[17:]) == string(checks[sum%11])
+}
+
+// validBankCard 以 Luhn 算法校验银行卡号
+func validBankCard(card string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(card)
+	if len(digits) < 16 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
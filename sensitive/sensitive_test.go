@@ -0,0 +1,50 @@
+package sensitive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	content := "# 客户信息\n\n" +
+		"身份证号：11010519491231002X，手机 13812345678。\n" +
+		"无效身份证 110105194912310021，订单号 20240101123456\n" +
+		"卡号 4111 1111 1111 1111，联系 zhang.san@corp.com 或 demo@example.com\n" +
+		"password: hunter2hunter2\n" +
+		"合同编号 HT-20240001\n"
+
+	custom, err := NewRule("contract_no", `HT-\d{8}`)
+	assert.NoError(t, err)
+	scanner, err := NewScanner(BuiltinNames(), []Rule{custom}, []string{`@example\.com$`})
+	assert.NoError(t, err)
+
+	var got []string
+	for _, f := range scanner.Scan("a.md", []byte(content)) {
+		got = append(got, f.Rule+":"+f.Match)
+		assert.Equal(t, "a.md", f.Path)
+	}
+	assert.Equal(t, []string{
+		"id_card:11010519491231002X",
+		"phone:13812345678",
+		"bank_card:4111 1111 1111 1111",
+		"email:zhang.san@corp.com",
+		"secret:password: hunter2hunter2",
+		"contract_no:HT-20240001",
+	}, got)
+
+	findings := scanner.Scan("a.md", []byte(content))
+	assert.Equal(t, 3, findings[0].Line)
+	assert.Equal(t, 5, findings[2].Line)
+
+	_, err = NewScanner([]string{"unknown"}, nil, nil)
+	assert.Error(t, err)
+	_, err = NewRule("bad", "(")
+	assert.Error(t, err)
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "138****5678", Mask("13812345678"))
+	assert.Equal(t, "a****b", Mask("a1234b"))
+	assert.Equal(t, "***", Mask("abc"))
+}
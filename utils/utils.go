@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/zhihanggg/gitdoc-cli/log"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // GetLocalHostIp 通过发送udp请求来获取本机ip
@@ -33,7 +35,7 @@ func GetLocalHostIp() (ip string, err error) {
 
 // ReadFile 读取并文件
 func ReadFile(file string) (string, error) {
-	log.Trace("ReadFile:", file)
+	log.Trace("ReadFile: %s", file)
 	content, err := os.ReadFile(file)
 	if err != nil {
 		return "", fmt.Errorf("read file:%s error:%v", file, err)
@@ -150,6 +152,25 @@ func GetParamPrefix(cmd *cobra.Command) string {
 	return completePrefix[strings.Index(completePrefix, ".")+1:]
 }
 
+// GetFlagParamKeys 返回 root 及其子命令全部命令行参数在 viper 中对应的名称，如 'create.project-name'
+func GetFlagParamKeys(root *cobra.Command) []string {
+	var keys []string
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		prefix := GetParamPrefix(cmd)
+		visit := func(flag *pflag.Flag) {
+			keys = append(keys, prefix+flag.Name)
+		}
+		cmd.LocalFlags().VisitAll(visit)
+		cmd.InheritedFlags().VisitAll(visit)
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	return keys
+}
+
 func parseKeyValue(line string) (string, string) {
 	parts := strings.SplitN(line, "=", 2)
 	if len(parts) != 2 {
@@ -193,7 +214,7 @@ func ParseStrToMap(str string) map[string]string {
 	}
 	var result map[string]string
 	if err := json.Unmarshal([]byte(str), &result); err != nil {
-		log.Error("%s Unmarshal to map err:%v", err)
+		log.Error("%s Unmarshal to map err:%v", str, err)
 		return nil
 	}
 	return result
@@ -225,6 +246,64 @@ func ExecCmd(cmd string) (string, error) {
 	return string(output), nil
 }
 
+// ShellQuote 将参数转义为可以安全拼接到 sh 命令中的字符串
+func ShellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// ShellQuoteAll 转义多个参数并以空格拼接
+func ShellQuoteAll(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, ShellQuote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// sizeUnits 文件大小单位，按 1024 进位
+var sizeUnits = []string{"B", "KB", "MB", "GB"}
+
+// ParseSize 解析文件大小，如 512KB、20MB，不带单位时按字节计算
+func ParseSize(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	multiplier := int64(1)
+	for i := len(sizeUnits) - 1; i >= 0; i-- {
+		if strings.HasSuffix(s, sizeUnits[i]) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, sizeUnits[i]))
+			multiplier = int64(1) << (10 * i)
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无法解析文件大小 %q，格式如 512KB、20MB", raw)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// FormatSize 将字节数格式化为便于阅读的大小，如 1.5MB
+func FormatSize(size int64) string {
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(sizeUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%dB", size)
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + sizeUnits[unit]
+}
+
+// IsTerminal 文件是否是终端
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // ScanFilesByExt 递归扫描指定目录下的特定扩展名文件
 func ScanFilesByExt(root string, extensions []string) ([]string, error) {
 	var files []string
@@ -249,9 +328,12 @@ func ScanFilesByExt(root string, extensions []string) ([]string, error) {
 	return files, err
 }
 
-// ConvertDocToMarkdown 使用pandoc将doc/docx文件转换为markdown
-func ConvertDocToMarkdown(docPath, mdPath string) error {
-	cmd := fmt.Sprintf("pandoc -s \"%s\" --extract-media=. -t markdown -o \"%s\"", docPath, mdPath)
+// ConvertDocToMarkdown 使用pandoc将doc/docx文件转换为markdown，args 为额外的 pandoc 参数，为空时使用默认参数
+func ConvertDocToMarkdown(docPath, mdPath string, args ...string) error {
+	if len(args) == 0 {
+		args = []string{"--extract-media=.", "-t", "markdown"}
+	}
+	cmd := fmt.Sprintf("pandoc -s \"%s\" %s -o \"%s\"", docPath, strings.Join(args, " "), mdPath)
 	_, err := ExecCmd(cmd)
 	return err
 }